@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"image"
+)
+
+// parseActiveRect reads -active-rect as x,y,w,h and checks it lies on a
+// grid of the given size
+func parseActiveRect(text string, width, height int) (image.Rectangle, error) {
+	var x, y, w, h int
+	if _, err := fmt.Sscanf(text, "%d,%d,%d,%d", &x, &y, &w, &h); err != nil {
+		return image.Rectangle{}, fmt.Errorf("bad -active-rect %q, expected x,y,w,h", text)
+	}
+	rect := image.Rect(x, y, x+w, y+h)
+	if w < 1 || h < 1 || !rect.In(image.Rect(0, 0, width, height)) {
+		return image.Rectangle{}, fmt.Errorf("-active-rect %q is not inside the %dx%d grid", text, width, height)
+	}
+	return rect, nil
+}
+
+// activeArea is the part of the grid Update computes, all of it unless an
+// active rectangle was set
+func (g *Game) activeArea() image.Rectangle {
+	if g.active.Empty() {
+		return image.Rect(0, 0, g.width, g.height)
+	}
+	return g.active
+}
+
+// copyFrozen copies the cells outside the active rectangle, and their ages
+// and aux values, into the next generation unchanged. Updating in place the
+// cells are already there, only the ages and aux values are copied.
+func (g *Game) copyFrozen() {
+	area := g.active
+	for x := range g.width {
+		if x < area.Min.X || x >= area.Max.X {
+			if !g.inPlace {
+				copy(g.nextGrid[x], g.grid[x])
+			}
+			if g.age != nil {
+				copy(g.nextAge[x], g.age[x])
+			}
+			if g.aux != nil {
+				copy(g.nextAux[x], g.aux[x])
+			}
+			continue
+		}
+		if !g.inPlace {
+			copy(g.nextGrid[x][:area.Min.Y], g.grid[x][:area.Min.Y])
+			copy(g.nextGrid[x][area.Max.Y:], g.grid[x][area.Max.Y:])
+		}
+		if g.age != nil {
+			copy(g.nextAge[x][:area.Min.Y], g.age[x][:area.Min.Y])
+			copy(g.nextAge[x][area.Max.Y:], g.age[x][area.Max.Y:])
+		}
+		if g.aux != nil {
+			copy(g.nextAux[x][:area.Min.Y], g.aux[x][:area.Min.Y])
+			copy(g.nextAux[x][area.Max.Y:], g.aux[x][area.Max.Y:])
+		}
+	}
+}
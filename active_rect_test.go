@@ -0,0 +1,66 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestParseActiveRect(t *testing.T) {
+	rect, err := parseActiveRect("10,5,30,20", 60, 40)
+	if err != nil || rect != image.Rect(10, 5, 40, 25) {
+		t.Errorf("got %v, %v", rect, err)
+	}
+	if _, err := parseActiveRect("0,0,60,40", 60, 40); err != nil {
+		t.Errorf("the whole grid: %v", err)
+	}
+	for _, text := range []string{"0,0,61,10", "-1,0,5,5", "1,1,0,3", "10,10,5", "a"} {
+		if _, err := parseActiveRect(text, 60, 40); err == nil {
+			t.Errorf("%q accepted", text)
+		}
+	}
+}
+
+func TestActiveRectFreezesOutside(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		schedule Schedule
+		inPlace  bool
+	}{
+		{"tiles", Tiles, false},
+		{"static", Static, false},
+		{"in place", Static, true},
+	} {
+		g := makeGame(60, 40)
+		g.fillSeeded(9, nil)
+		g.EnableAge()
+		g.schedule = c.schedule
+		g.inPlace = c.inPlace
+		g.workers = 3
+		g.active = image.Rect(10, 5, 40, 25)
+		start := makeGame(60, 40)
+		start.Stamp(g, 0, 0, Overwrite)
+
+		changedInside := false
+		for range 50 {
+			g.Update()
+			g.SwapAndCount()
+			counted := g.population
+			g.countPopulation()
+			if counted != g.population {
+				t.Fatalf("%s: population %v, counted again %v", c.name, counted, g.population)
+			}
+			for x := range g.width {
+				for y := range g.height {
+					changed := g.grid[x][y] != start.grid[x][y]
+					if !(image.Point{x, y}).In(g.active) && changed {
+						t.Fatalf("%s: cell %d,%d outside the active rectangle changed", c.name, x, y)
+					}
+					changedInside = changedInside || changed
+				}
+			}
+		}
+		if !changedInside {
+			t.Errorf("%s: nothing changed inside the active rectangle", c.name)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Number of colors live cells are shaded with in ColorAge
+const AGE_STEPS = 8
+
+// EnableAge starts tracking how many generations each live cell has survived.
+// Every cell starts at age 0.
+func (g *Game) EnableAge() {
+	if g.age != nil {
+		return
+	}
+	g.age = make([][]uint16, g.width)
+	g.nextAge = make([][]uint16, g.width)
+	for x := range g.width {
+		g.age[x] = make([]uint16, g.height)
+		g.nextAge[x] = make([]uint16, g.height)
+	}
+}
+
+// nextCellAge returns the age of a cell whose next state is next.
+// Surviving cells grow older, births and deaths start over at 0.
+func (g *Game) nextCellAge(x, y int, next uint8) uint16 {
+	age := g.age[x][y]
+	if (next != BLUE && next != ORANGE) || next != g.grid[x][y] {
+		return 0
+	}
+	if age == math.MaxUint16 {
+		return age
+	}
+	return age + 1
+}
+
+// ageStep maps an age to one of AGE_STEPS colors, doubling the ages covered by each step
+func ageStep(age uint16) int {
+	return min(bits.Len16(age), AGE_STEPS-1)
+}
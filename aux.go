@@ -0,0 +1,74 @@
+package main
+
+import "math"
+
+// Number of colors cells are shaded with in ColorAux
+const AUX_STEPS = 16
+
+// Colors for aux values in ColorAux, from 0 to 255
+var auxGradient = gradient(rgba(0xFF, 0x33, 0x00), rgba(0x00, 0x22, 0x44), AUX_STEPS)
+
+// AuxFunc returns the aux value of a cell in the next generation, given its
+// next state. Update calls it from its workers, so it may read the game but
+// must not change it. The cell's own column still holds the current states,
+// with -grid-buffer-reuse the columns left of it may already be updated.
+type AuxFunc func(g *Game, x, y int, next uint8) uint8
+
+// EnableAux gives every cell a uint8 of its own, 0 at first, for a tag or a
+// reading that moves along with the generations. It is kept in two buffers
+// like the grid and Swap swaps them together. fn decides the values of each
+// new generation, with a nil fn every cell keeps its value.
+func (g *Game) EnableAux(fn AuxFunc) {
+	g.auxFunc = fn
+	if g.aux != nil {
+		return
+	}
+	g.aux = make([][]uint8, g.width)
+	g.nextAux = make([][]uint8, g.width)
+	for x := range g.width {
+		g.aux[x] = make([]uint8, g.height)
+		g.nextAux[x] = make([]uint8, g.height)
+	}
+}
+
+// Aux returns the aux value of a cell, 0 unless EnableAux was called
+func (g *Game) Aux(x, y int) uint8 {
+	if g.aux == nil {
+		return 0
+	}
+	return g.aux[x][y]
+}
+
+// SetAux changes the aux value of a cell, it does nothing unless EnableAux
+// was called
+func (g *Game) SetAux(x, y int, value uint8) {
+	if g.aux != nil {
+		g.aux[x][y] = value
+	}
+}
+
+// nextCellAux returns the aux value of a cell whose next state is next
+func (g *Game) nextCellAux(x, y int, next uint8) uint8 {
+	if g.auxFunc == nil {
+		return g.aux[x][y]
+	}
+	return g.auxFunc(g, x, y, next)
+}
+
+// auxQuiet is the AuxFunc of -color aux: the generations since the cell
+// last changed state, up to 255
+func auxQuiet(g *Game, x, y int, next uint8) uint8 {
+	quiet := g.aux[x][y]
+	if next != g.grid[x][y] {
+		return 0
+	}
+	if quiet == math.MaxUint8 {
+		return quiet
+	}
+	return quiet + 1
+}
+
+// auxStep maps an aux value to one of AUX_STEPS colors
+func auxStep(value uint8) int {
+	return int(value) * AUX_STEPS / (math.MaxUint8 + 1)
+}
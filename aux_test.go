@@ -0,0 +1,73 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestAuxQuiet(t *testing.T) {
+	for _, buffers := range []GridBuffers{DoubleBuffer, ReuseBuffer} {
+		for _, active := range []image.Rectangle{{}, image.Rect(5, 5, 30, 20)} {
+			g, err := newEmptyGame(40, 30, buffers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.fillSeeded(3, nil)
+			g.active = active
+			g.EnableAux(auxQuiet)
+
+			// Generations each cell has kept its state, frozen cells keep
+			// their count
+			want := make([][]int, g.width)
+			for x := range want {
+				want[x] = make([]int, g.height)
+			}
+			prev := makeGame(g.width, g.height)
+			for gen := 1; gen <= 300; gen++ {
+				prev.Stamp(g, 0, 0, Overwrite)
+				g.Update()
+				g.Swap()
+				for x := range g.width {
+					for y := range g.height {
+						if g.grid[x][y] != prev.grid[x][y] {
+							want[x][y] = 0
+						} else if image.Pt(x, y).In(g.activeArea()) {
+							want[x][y] = min(255, want[x][y]+1)
+						}
+						if int(g.Aux(x, y)) != want[x][y] {
+							t.Fatalf("in place %v, active %v, generation %d: cell %d,%d aux %d, want %d",
+								buffers == ReuseBuffer, active, gen, x, y, g.Aux(x, y), want[x][y])
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestAuxKeepAndSet(t *testing.T) {
+	g := makeGame(10, 10)
+	g.SetAux(1, 1, 9)
+	if g.Aux(1, 1) != 0 || g.aux != nil {
+		t.Fatal("aux values kept before EnableAux")
+	}
+
+	// Without a func every cell keeps its value through both buffers
+	g.EnableAux(nil)
+	g.SetAux(1, 1, 9)
+	for range 3 {
+		g.Update()
+		g.Swap()
+	}
+	if g.Aux(1, 1) != 9 {
+		t.Errorf("aux value %d after three generations, want 9", g.Aux(1, 1))
+	}
+}
+
+func TestAuxStep(t *testing.T) {
+	for value, want := range map[uint8]int{0: 0, 15: 0, 16: 1, 255: AUX_STEPS - 1} {
+		if got := auxStep(value); got != want {
+			t.Errorf("aux %d shaded with step %d, want %d", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"math/rand"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Run with go test -run '^$' -bench . -benchmem
+
+func benchGame(b *testing.B, width, height int) *Game {
+	b.Helper()
+	g, err := NewGame(width, height, rand.New(rand.NewSource(1)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return g
+}
+
+func BenchmarkUpdate(b *testing.B) {
+	for _, size := range []int{100, 500, 1000} {
+		counts := []int{1, 4, runtime.NumCPU()}
+		slices.Sort(counts)
+		for _, workers := range slices.Compact(counts) {
+			b.Run(fmt.Sprintf("%dx%d/workers=%d", size, size, workers), func(b *testing.B) {
+				g := benchGame(b, size, size)
+				g.workers = workers
+				b.ReportAllocs()
+				b.ResetTimer()
+				for range b.N {
+					g.Update()
+					g.Swap()
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkDraw draws into a software renderer, so it needs no window
+func BenchmarkDraw(b *testing.B) {
+	const SIZE = 500
+	surface, err := sdl.CreateRGBSurface(0, SIZE, SIZE, 32, 0, 0, 0, 0)
+	if err != nil {
+		b.Skip(err)
+	}
+	defer surface.Free()
+	renderer, err := sdl.CreateSoftwareRenderer(surface)
+	if err != nil {
+		b.Skip(err)
+	}
+	defer renderer.Destroy()
+
+	defer func(mode RenderMode) { renderMode = mode }(renderMode)
+	for _, mode := range []struct {
+		name string
+		mode RenderMode
+	}{
+		{"points", RenderPoints},
+		{"texture", RenderTexture},
+		{"squares", RenderSquares},
+		{"circles", RenderCircles},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			renderMode = mode.mode
+			g := benchGame(b, SIZE, SIZE)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				g.drawBoard(renderer)
+			}
+		})
+	}
+}
+
+// BenchmarkOutput writes a frame per protocol to io.Discard. The board
+// alternates between two generations so the delta protocols have changes
+// to write.
+func BenchmarkOutput(b *testing.B) {
+	defer func(p Protocol) { protocol = p }(protocol)
+	for _, name := range slices.Sorted(maps.Keys(protocolNames)) {
+		p := protocolNames[name]
+		if p == Off {
+			continue
+		}
+		b.Run(name, func(b *testing.B) {
+			protocol = p
+			g := benchGame(b, 500, 500)
+			type frame struct {
+				grid       [][]uint8
+				population [MAX_STATE + 1]int
+			}
+			var frames [2]frame
+			for i := range frames {
+				frames[i].population = g.population
+				for _, column := range g.grid {
+					frames[i].grid = append(frames[i].grid, slices.Clone(column))
+				}
+				g.Update()
+				g.Swap()
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := range b.N {
+				g.grid, g.population = frames[i%2].grid, frames[i%2].population
+				if err := g.writeFrame(io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// sparseBoard is a 1000x1000 board with under 1% of its cells not EMPTY
+func sparseBoard(b *testing.B) *Game {
+	b.Helper()
+	g, err := newEmptyGame(1000, 1000, DoubleBuffer)
+	if err != nil {
+		b.Fatal(err)
+	}
+	g.fillSeeded(5, func(x, y int) bool { return cellRand(6, x, y)%100 == 0 })
+	return g
+}
+
+// Counted cells are kept here so the loops are not optimized away
+var benchLive int
+
+func BenchmarkFullScan(b *testing.B) {
+	g := sparseBoard(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		for y := range g.height {
+			for x := range g.width {
+				if g.grid[x][y] != EMPTY {
+					benchLive++
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkForEachLive(b *testing.B) {
+	g := sparseBoard(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		g.ForEachLive(func(x, y int, state uint8) { benchLive++ })
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Window pixels between the boards of a Boards window
+const BOARD_GAP = 8
+
+// Boards advances several independent games together and tiles them into
+// one window, filling rows of cols boards from the top left
+type Boards struct {
+	games []*Game
+	cols  int
+}
+
+func NewBoards(games []*Game) *Boards {
+	cols := int(math.Ceil(math.Sqrt(float64(len(games)))))
+	return &Boards{games: games, cols: cols}
+}
+
+// WindowSize is the size of a window that fits every board at -zoom
+func (b *Boards) WindowSize() (width, height int) {
+	rows := (len(b.games) + b.cols - 1) / b.cols
+	w, h := *zoom*b.games[0].width, *zoom*b.games[0].height
+	return b.cols*w + (b.cols-1)*BOARD_GAP, rows*h + (rows-1)*BOARD_GAP
+}
+
+// Step advances every board by one generation, each on its own goroutine
+func (b *Boards) Step() {
+	var wg sync.WaitGroup
+	for _, game := range b.games {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			game.Update()
+			game.Swap()
+		}()
+	}
+	wg.Wait()
+}
+
+// Draw draws every board into its tile of the window
+func (b *Boards) Draw(renderer *sdl.Renderer) {
+	renderer.SetDrawColor(0x33, 0x33, 0x33, 0xFF)
+	renderer.Clear()
+	w, h := *zoom*b.games[0].width, *zoom*b.games[0].height
+	for i, game := range b.games {
+		x := int32((i % b.cols) * (w + BOARD_GAP))
+		y := int32((i / b.cols) * (h + BOARD_GAP))
+		setDrawColor(renderer, windowPalette[EMPTY])
+		renderer.FillRect(&sdl.Rect{X: x, Y: y, W: int32(w), H: int32(h)})
+		game.drawInViewport(renderer, x, y)
+	}
+	renderer.Present()
+}
+
+// Run opens the window and steps the boards until it is closed. The first
+// board takes the keys, its pause holds all of them.
+func (b *Boards) Run(title string) error {
+	width, height := b.WindowSize()
+	window, renderer, err := openWindow(width, height)
+	if err != nil {
+		return err
+	}
+	defer sdl.Quit()
+	defer window.Destroy()
+	defer renderer.Destroy()
+	window.SetTitle(title)
+
+	first := b.games[0]
+	for !first.quit {
+		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			first.handleEvent(event)
+		}
+		if first.resized {
+			for _, game := range b.games {
+				game.releaseTexture()
+			}
+			first.resized = false
+		}
+		b.Draw(renderer)
+		printFPS()
+
+		if first.paused {
+			first.wait(10 * time.Millisecond)
+			continue
+		}
+		b.Step()
+	}
+	flushFPS()
+	return nil
+}
+
+// runBoards runs -boards independent games, each with the rule and seed
+// picked for it by -board-rules and -board-seeds
+func runBoards(count int) error {
+	if !*visual {
+		return fmt.Errorf("-boards needs the window")
+	}
+	texts := strings.Split(*boardRules, ",")
+	if *boardRules == "" {
+		texts = []string{*ruleFlag}
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	seeds := make([]int64, count)
+	for i := range seeds {
+		seeds[i] = *seed + int64(i)
+	}
+	if *boardSeeds != "" {
+		fields := strings.Split(*boardSeeds, ",")
+		if len(fields) != count {
+			return fmt.Errorf("-board-seeds has %d seeds for %d boards", len(fields), count)
+		}
+		for i, field := range fields {
+			var err error
+			if seeds[i], err = strconv.ParseInt(strings.TrimSpace(field), 10, 64); err != nil {
+				return fmt.Errorf("bad seed %q in -board-seeds", field)
+			}
+		}
+	}
+	if err := checkMemory(count*(*width), *height); err != nil {
+		return err
+	}
+
+	games := make([]*Game, count)
+	titles := make([]string, count)
+	for i := range games {
+		// Rules are reused from the start when there are fewer than boards
+		rule, err := ParseRule(texts[i%len(texts)])
+		if err != nil {
+			return err
+		}
+		games[i], err = newSeededGame(*width, *height, seeds[i])
+		if err != nil {
+			return err
+		}
+		games[i].rule = rule
+		if err := configureGame(games[i]); err != nil {
+			return err
+		}
+		titles[i] = fmt.Sprintf("%s #%d", rule, seeds[i])
+	}
+
+	if *dryRun {
+		for _, game := range games {
+			writeSummary(os.Stdout, game)
+		}
+		return nil
+	}
+	return NewBoards(games).Run(strings.Join(titles, " | "))
+}
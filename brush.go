@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Largest brush, in cells across
+const MAX_BRUSH_SIZE = 64
+
+var brushColor = rgba(0xFF, 0xFF, 0xFF)
+
+func checkBrushShape(shape string) error {
+	if shape != "square" && shape != "circle" {
+		return fmt.Errorf("unknown brush shape %q, expected square or circle", shape)
+	}
+	return nil
+}
+
+// Brush paints cells under the mouse: BLUE with the left button, ORANGE
+// with the right one and EMPTY with the middle one
+type Brush struct {
+	size   int
+	circle bool
+	// Cursor in window pixels, valid once the mouse has moved over the window
+	x, y    int32
+	hovered bool
+	// State painted while a button is held, 0 for none
+	button uint8
+}
+
+func newBrush() Brush {
+	return Brush{size: max(1, min(*brushSize, MAX_BRUSH_SIZE)), circle: *brushShape == "circle"}
+}
+
+// resize grows or shrinks the brush by delta cells across
+func (b *Brush) resize(delta int) {
+	b.size = max(1, min(b.size+delta, MAX_BRUSH_SIZE))
+}
+
+// handleMouse paints on button presses and while dragging with one held
+func (g *Game) handleMouse(event sdl.Event) {
+	if g.tilePreview {
+		g.brush.hovered = false
+		return
+	}
+	switch e := event.(type) {
+	case *sdl.MouseButtonEvent:
+		g.brush.x, g.brush.y, g.brush.hovered = e.X, e.Y, true
+		if e.State != sdl.PRESSED {
+			g.brush.button = 0
+			return
+		}
+		g.brush.button = e.Button
+		g.paint()
+	case *sdl.MouseMotionEvent:
+		g.brush.x, g.brush.y, g.brush.hovered = e.X, e.Y, true
+		if g.brush.button != 0 {
+			g.paint()
+		}
+	}
+}
+
+// brushCorner is the top left cell of the brush at the cursor
+func (g *Game) brushCorner() (int, int) {
+	z := int32(*zoom)
+	return g.viewX + int(g.brush.x/z) - g.brush.size/2, g.viewY + int(g.brush.y/z) - g.brush.size/2
+}
+
+// paint sets the cells under the brush through Set, wrapping or dropping
+// them past the edges like a placed pattern. The next Update reads them.
+// The cells are collected first and set through edit, so they reach the
+// simulation under RunDecoupled.
+func (g *Game) paint() {
+	var state uint8
+	switch g.brush.button {
+	case sdl.BUTTON_LEFT:
+		state = BLUE
+	case sdl.BUTTON_RIGHT:
+		state = ORANGE
+	case sdl.BUTTON_MIDDLE:
+		state = EMPTY
+	default:
+		return
+	}
+	left, top := g.brushCorner()
+	size := g.brush.size
+	r := float64(size) / 2
+	var cells []Cell
+	for i := range size {
+		for j := range size {
+			if g.brush.circle && math.Hypot(float64(i)+0.5-r, float64(j)+0.5-r) > r {
+				continue
+			}
+			if x, y, ok := g.resolvePlaced(left+i, top+j); ok {
+				cells = append(cells, Cell{x, y, state})
+			}
+		}
+	}
+	g.edit(func(g *Game) {
+		for _, cell := range cells {
+			g.Set(cell.X, cell.Y, cell.State)
+		}
+	})
+}
+
+// drawBrush outlines the brush at the cursor
+func (g *Game) drawBrush(renderer *sdl.Renderer) {
+	if !g.brush.hovered {
+		return
+	}
+	z := *zoom
+	left, top := g.brushCorner()
+	x, y := int32((left-g.viewX)*z), int32((top-g.viewY)*z)
+	size := int32(g.brush.size * z)
+
+	setDrawColor(renderer, brushColor)
+	if !g.brush.circle || size < 4 {
+		renderer.DrawRect(&sdl.Rect{X: x, Y: y, W: size, H: size})
+		return
+	}
+	r := float64(size) / 2
+	steps := max(16, int(2*math.Pi*r))
+	points := make([]sdl.Point, steps)
+	for i := range points {
+		a := 2 * math.Pi * float64(i) / float64(steps)
+		points[i] = sdl.Point{X: x + int32(r+r*math.Cos(a)), Y: y + int32(r+r*math.Sin(a))}
+	}
+	renderer.DrawPoints(points)
+}
@@ -0,0 +1,49 @@
+package main
+
+import "math"
+
+// Centroid returns the mean position of the BLUE and ORANGE cells. Along an
+// axis that wraps around it is the circular mean, so a group straddling the
+// edge is centered on the edge and not in the middle of the board. any is
+// false for a board without live cells.
+func (g *Game) Centroid() (cx, cy float64, any bool) {
+	var n int
+	var sumX, sumY float64
+	var sinX, cosX, sinY, cosY float64
+	for x := range g.width {
+		ax := 2 * math.Pi * float64(x) / float64(g.width)
+		for y, state := range g.grid[x] {
+			if !isLive(state) {
+				continue
+			}
+			ay := 2 * math.Pi * float64(y) / float64(g.height)
+			n++
+			sumX += float64(x)
+			sumY += float64(y)
+			sinX += math.Sin(ax)
+			cosX += math.Cos(ax)
+			sinY += math.Sin(ay)
+			cosY += math.Cos(ay)
+		}
+	}
+	if n == 0 {
+		return 0, 0, false
+	}
+	cx, cy = sumX/float64(n), sumY/float64(n)
+	if g.edge&WrapX != 0 {
+		cx = circularMean(sinX, cosX, g.width)
+	}
+	if g.edge&WrapY != 0 {
+		cy = circularMean(sinY, cosY, g.height)
+	}
+	return cx, cy, true
+}
+
+// circularMean turns summed unit vectors back into a position in [0, size)
+func circularMean(sin, cos float64, size int) float64 {
+	p := math.Atan2(sin, cos) * float64(size) / (2 * math.Pi)
+	if p < 0 {
+		p += float64(size)
+	}
+	return p
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCentroid(t *testing.T) {
+	g := makeGame(100, 50)
+	if _, _, any := g.Centroid(); any {
+		t.Fatal("an empty board has a centroid")
+	}
+
+	// Away from the edges it is the plain mean, decaying cells left out
+	g.grid[10][20] = ORANGE
+	g.grid[12][20] = BLUE
+	g.grid[40][40] = DEAD
+	if cx, cy, any := g.Centroid(); !any || !near(cx, 11) || !near(cy, 20) {
+		t.Errorf("centroid %v,%v, want 11,20", cx, cy)
+	}
+}
+
+func TestCentroidAcrossSeam(t *testing.T) {
+	// A block split over the four corners of the torus
+	g := makeGame(100, 50)
+	for _, cell := range [][2]int{{99, 49}, {0, 49}, {99, 0}, {0, 0}} {
+		g.grid[cell[0]][cell[1]] = BLUE
+	}
+	for _, c := range []struct {
+		edge   EdgeMode
+		cx, cy float64
+	}{
+		{Toroidal, 99.5, 49.5},
+		{WrapX, 99.5, 24.5},
+		{WrapY, 49.5, 49.5},
+		{Bounded, 49.5, 24.5},
+	} {
+		g.edge = c.edge
+		if cx, cy, _ := g.Centroid(); !near(cx, c.cx) || !near(cy, c.cy) {
+			t.Errorf("edge %d: centroid %v,%v, want %v,%v", c.edge, cx, cy, c.cx, c.cy)
+		}
+	}
+}
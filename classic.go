@@ -0,0 +1,48 @@
+package main
+
+// classicChange is CellChange for the two state engine of -classic: a live
+// cell that does not survive turns EMPTY at once and every birth is BLUE
+func (g *Game) classicChange(x, y int, hist *[9]int) uint8 {
+	blue_count, orange_count := g.tallyNeighbors(x, y, hist)
+	count := blue_count + orange_count
+
+	cell := g.grid[x][y]
+	if cell == BLUE || cell == ORANGE {
+		if g.rule.Survival[count] {
+			return cell
+		}
+		return EMPTY
+	}
+	if g.rule.Birth[count] {
+		return BLUE
+	}
+	return EMPTY
+}
+
+// SetClassic switches between the color engine and the two state classic
+// one. Going classic turns ORANGE cells BLUE and decaying cells EMPTY, so
+// only live and dead cells are left. The rule stays the same.
+func (g *Game) SetClassic(on bool) {
+	g.classic = on
+	if !on {
+		return
+	}
+	for x := range g.width {
+		for y := range g.height {
+			switch state := g.grid[x][y]; {
+			case state == ORANGE:
+				g.Set(x, y, BLUE)
+			case state >= DEAD:
+				g.Set(x, y, EMPTY)
+			}
+		}
+	}
+}
+
+// engineName names the engine the game runs on, for messages
+func (g *Game) engineName() string {
+	if g.classic {
+		return "classic"
+	}
+	return "color"
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// parseCompare splits and checks the rules given to -compare
+func parseCompare(spec string) ([]Rule, error) {
+	texts := strings.Split(spec, ",")
+	if len(texts) != 2 {
+		return nil, fmt.Errorf("bad -compare %q, expected two rules like B3/S23,B36/S23", spec)
+	}
+	if !*visual {
+		return nil, fmt.Errorf("-compare needs the window")
+	}
+	rules := make([]Rule, len(texts))
+	for i, text := range texts {
+		var err error
+		if rules[i], err = ParseRule(text); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// runCompare shows one board per rule next to each other, all starting from
+// the same random cells and advancing in lockstep until the window is closed
+func runCompare(spec string) error {
+	rules, err := parseCompare(spec)
+	if err != nil {
+		return err
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	if err := checkMemory(len(rules)*(*width), *height); err != nil {
+		return err
+	}
+	games := make([]*Game, len(rules))
+	for i, rule := range rules {
+		games[i], err = newSeededGame(*width, *height, *seed)
+		if err != nil {
+			return err
+		}
+		games[i].rule = rule
+		if err := configureGame(games[i]); err != nil {
+			return err
+		}
+	}
+
+	if *dryRun {
+		for _, game := range games {
+			writeSummary(os.Stdout, game)
+		}
+		return nil
+	}
+
+	return NewBoards(games).Run(strings.ReplaceAll(spec, ",", " | "))
+}
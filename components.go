@@ -0,0 +1,72 @@
+package main
+
+// Component is a group of live cells connected through the neighborhood of
+// the game. Cells are in grid coordinates, X and Y unwrapped across wrapping
+// edges so a component that straddles one stays in one piece.
+type Component struct {
+	Cells []ComponentCell
+}
+
+type ComponentCell struct {
+	GX, GY int // on the grid
+	X, Y   int // unwrapped, relative to the first cell found
+}
+
+// Components finds every group of connected BLUE and ORANGE cells.
+// Decaying cells do not count as neighbors, so they do not join groups.
+func (g *Game) Components() []Component {
+	return g.labelComponents(make([]int32, g.width*g.height))
+}
+
+// labelComponents is Components writing the 1-based index of the component
+// of each live cell into labels, indexed by x*height+y, and 0 elsewhere
+func (g *Game) labelComponents(labels []int32) []Component {
+	clear(labels)
+	var components []Component
+	var queue []ComponentCell
+	for x := range g.width {
+		for y, state := range g.grid[x] {
+			if !isLive(state) || labels[x*g.height+y] != 0 {
+				continue
+			}
+			label := int32(len(components) + 1)
+			labels[x*g.height+y] = label
+			queue = append(queue[:0], ComponentCell{x, y, 0, 0})
+			var c Component
+			for len(queue) > 0 {
+				cell := queue[len(queue)-1]
+				queue = queue[:len(queue)-1]
+				c.Cells = append(c.Cells, cell)
+				g.forEachNeighbor(cell.GX, cell.GY, func(nx, ny int) {
+					if !isLive(g.grid[nx][ny]) || labels[nx*g.height+ny] != 0 {
+						return
+					}
+					labels[nx*g.height+ny] = label
+					queue = append(queue, ComponentCell{
+						nx, ny,
+						cell.X + wrapDelta(nx-cell.GX, g.width),
+						cell.Y + wrapDelta(ny-cell.GY, g.height),
+					})
+				})
+			}
+			components = append(components, c)
+		}
+	}
+	return components
+}
+
+func isLive(state uint8) bool {
+	return state == BLUE || state == ORANGE
+}
+
+// wrapDelta turns the step between two neighbors that crossed a wrapping
+// edge back into a step of one cell
+func wrapDelta(d, size int) int {
+	switch {
+	case d > 1:
+		return d - size
+	case d < -1:
+		return d + size
+	}
+	return d
+}
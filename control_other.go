@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// There are no user signals here, so a game cannot be controlled by signals
+var pauseSignal, stepSignal os.Signal
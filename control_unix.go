@@ -0,0 +1,14 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// Signals that pause and step a running game, see Game.control
+var (
+	pauseSignal os.Signal = syscall.SIGUSR1
+	stepSignal  os.Signal = syscall.SIGUSR2
+)
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"runtime"
+	"time"
+)
+
+// CPUThrottle sleeps between generations so that Update uses at most a
+// share of all cores on average. Update is assumed to keep all of its
+// workers busy while it runs, so this costs latency rather than measuring
+// the real load. Time spent outside Update is not counted.
+type CPUThrottle struct {
+	// Fraction of all cores busy while Update runs, and the fraction allowed
+	share, limit float64
+	// Totals since the start, of Update and of the time slept
+	busy, idle time.Duration
+
+	now func() time.Time
+}
+
+// NewCPUThrottle caps Update at percent of all cpus when it runs on workers
+// goroutines, 0 workers meaning one per CPU
+func NewCPUThrottle(percent float64, workers, cpus int) *CPUThrottle {
+	if workers <= 0 || workers > cpus {
+		workers = cpus
+	}
+	return &CPUThrottle{
+		share: float64(workers) / float64(cpus),
+		limit: percent / 100,
+		now:   time.Now,
+	}
+}
+
+func newCPUThrottle(percent float64, workers int) *CPUThrottle {
+	return NewCPUThrottle(percent, workers, runtime.NumCPU())
+}
+
+// Wait adds an Update that took the given time and sleeps until the
+// average is back under the limit. sleep may return early.
+func (t *CPUThrottle) Wait(took time.Duration, sleep func(time.Duration)) {
+	t.busy += took
+	owed := time.Duration(float64(t.busy)*t.share/t.limit) - t.busy - t.idle
+	for owed > 0 {
+		start := t.now()
+		sleep(owed)
+		slept := t.now().Sub(start)
+		if slept <= 0 {
+			break
+		}
+		t.idle += slept
+		owed -= slept
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCPUThrottleAverage(t *testing.T) {
+	for _, c := range []struct {
+		percent       float64
+		workers, cpus int
+		// Average share of all cores Update ends up using
+		want float64
+	}{
+		{25, 0, 8, 0.25},
+		{25, 2, 8, 0.25},
+		{10, 4, 4, 0.10},
+		// A single worker on 4 cores never gets to 50%, nothing to throttle
+		{50, 1, 4, 0.25},
+	} {
+		clock := time.Unix(0, 0)
+		throttle := NewCPUThrottle(c.percent, c.workers, c.cpus)
+		throttle.now = func() time.Time { return clock }
+		workers := c.workers
+		if workers == 0 {
+			workers = c.cpus
+		}
+
+		var used float64
+		for i := range 1000 {
+			took := time.Duration(5+i%7) * time.Millisecond
+			clock = clock.Add(took)
+			used += took.Seconds() * float64(workers) / float64(c.cpus)
+			// Every other sleep returns halfway
+			throttle.Wait(took, func(d time.Duration) {
+				if i%2 == 0 {
+					d /= 2
+				}
+				clock = clock.Add(d)
+			})
+		}
+		got := used / clock.Sub(time.Unix(0, 0)).Seconds()
+		if got > c.want+1e-6 || got < c.want*0.99 {
+			t.Errorf("%v%% of %d cpus with %d workers: used %.4f, want %.4f", c.percent, c.cpus, c.workers, got, c.want)
+		}
+	}
+}
+
+func TestCPUThrottleStuckClock(t *testing.T) {
+	// A sleep that takes no time must not spin forever
+	throttle := NewCPUThrottle(10, 1, 1)
+	clock := time.Unix(0, 0)
+	throttle.now = func() time.Time { return clock }
+	sleeps := 0
+	throttle.Wait(time.Second, func(time.Duration) { sleeps++ })
+	if sleeps != 1 {
+		t.Errorf("slept %d times on a stuck clock, want 1", sleeps)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// WriteCSV writes one line per row of the grid holding the state of every
+// cell in it as an integer, separated by commas, without a header. Rows are
+// written as they are formatted, so the whole file is never held in memory.
+func (g *Game) WriteCSV(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := make([]byte, 0, 2*g.width)
+	for y := range g.height {
+		line = line[:0]
+		for x := range g.width {
+			if x > 0 {
+				line = append(line, ',')
+			}
+			line = strconv.AppendUint(line, uint64(g.grid[x][y]), 10)
+		}
+		line = append(line, '\n')
+		if _, err := bw.Write(line); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// SaveCSV writes the board to path with WriteCSV
+func (g *Game) SaveCSV(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = g.WriteCSV(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// LoadCSV reads a board written by SaveCSV
+func LoadCSV(path string) (*Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	g, err := ParseCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return g, nil
+}
+
+// ParseCSV reads the format WriteCSV writes. The size comes from the number
+// of lines and the cells in the first one, every other line must have as
+// many. Blank lines at the end are ignored.
+func ParseCSV(r io.Reader) (*Game, error) {
+	var rows [][]uint8
+	blank := 0 // first blank line, only allowed after the last row
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		text, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		text = bytes.TrimRight(text, "\r\n")
+		switch {
+		case len(text) == 0:
+			if blank == 0 {
+				blank = line
+			}
+		case blank > 0:
+			return nil, fmt.Errorf("line %d: blank line inside the board", blank)
+		default:
+			row, rowErr := parseCSVRow(text, line)
+			if rowErr != nil {
+				return nil, rowErr
+			}
+			if len(rows) > 0 && len(row) != len(rows[0]) {
+				return nil, fmt.Errorf("line %d: %d cells, the first line has %d", line, len(row), len(rows[0]))
+			}
+			rows = append(rows, row)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("no cells")
+	}
+
+	g := makeGame(len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x, state := range row {
+			g.grid[x][y] = state
+		}
+	}
+	g.countPopulation()
+	return g, nil
+}
+
+// loadCSVPattern loads a CSV board as a pattern, for -pattern
+func loadCSVPattern(path string) (*Pattern, error) {
+	g, err := LoadCSV(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &Pattern{Width: g.width, Height: g.height}
+	g.ForEachLive(func(x, y int, state uint8) {
+		p.Cells = append(p.Cells, Cell{x, y, state})
+	})
+	return p, nil
+}
+
+func parseCSVRow(text []byte, line int) ([]uint8, error) {
+	fields := bytes.Split(text, []byte(","))
+	row := make([]uint8, len(fields))
+	for i, field := range fields {
+		state, err := strconv.ParseUint(string(bytes.TrimSpace(field)), 10, 8)
+		if err != nil || state > MAX_STATE {
+			return nil, fmt.Errorf("line %d, cell %d: %q is not a state from 0 to %d", line, i+1, field, MAX_STATE)
+		}
+		row[i] = uint8(state)
+	}
+	return row, nil
+}
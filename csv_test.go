@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	g, err := newEmptyGame(7, 5, DoubleBuffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.fillSeeded(4, nil)
+	g.Set(6, 4, MAX_STATE)
+	path := filepath.Join(t.TempDir(), "board.csv")
+	if err := g.SaveCSV(path); err != nil {
+		t.Fatal(err)
+	}
+	back, err := LoadCSV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.width != 7 || back.height != 5 || !back.Equal(g) || back.population != g.population {
+		t.Error("the board read back differs")
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	// Spaces around cells, CRLF line ends and blank lines at the end are fine
+	g, err := ParseCSV(strings.NewReader("0, 1,2\r\n3,0,1\r\n\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.width != 3 || g.height != 2 || g.grid[1][0] != BLUE || g.grid[0][1] != DEAD || g.population[EMPTY] != 2 {
+		t.Errorf("read as %dx%d %v", g.width, g.height, g.grid)
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	for text, want := range map[string]string{
+		"0,1\n1\n":                         "line 2: 1 cells",
+		"0,x\n":                            `line 1, cell 2: "x"`,
+		"0,-1\n":                           "cell 2",
+		"0,1\n\n1,1\n":                     "line 2: blank line",
+		"":                                 "no cells",
+		"\n\n":                             "no cells",
+		fmt.Sprintf("0,%d\n", MAX_STATE+1): "cell 2",
+	} {
+		if _, err := ParseCSV(strings.NewReader(text)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: got %v, want an error with %q", text, err, want)
+		}
+	}
+}
+
+func TestLoadCSVNamesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.csv")
+	if _, err := LoadCSV(path); err == nil || !strings.Contains(err.Error(), "missing.csv") {
+		t.Errorf("got %v", err)
+	}
+}
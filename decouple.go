@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Frame is a finished generation handed from the simulation to the window
+// and the output stream
+type Frame struct {
+	grid       [][]uint8
+	generation int
+	population [MAX_STATE + 1]int
+	// Published for changes made while paused, not for a new generation
+	edited bool
+}
+
+func newFrame(width, height int) *Frame {
+	grid := make([][]uint8, width)
+	for x := range grid {
+		grid[x] = make([]uint8, height)
+	}
+	return &Frame{grid: grid}
+}
+
+// TripleBuffer passes frames from one writer to one reader without either
+// waiting for the other. The writer fills Back and publishes it; the reader
+// takes the latest published frame. Neither ever sees a frame the other is
+// using, so a frame is never torn, and frames the reader was too slow for
+// are skipped.
+type TripleBuffer struct {
+	mu    sync.Mutex
+	back  *Frame // written by the writer only
+	ready *Frame // the latest published frame
+	front *Frame // read by the reader only
+	fresh bool
+}
+
+func NewTripleBuffer(width, height int) *TripleBuffer {
+	return &TripleBuffer{back: newFrame(width, height), ready: newFrame(width, height), front: newFrame(width, height)}
+}
+
+// Back is the frame the writer fills next
+func (t *TripleBuffer) Back() *Frame {
+	return t.back
+}
+
+// Publish makes the filled back frame the latest one
+func (t *TripleBuffer) Publish() {
+	t.mu.Lock()
+	t.back, t.ready = t.ready, t.back
+	t.fresh = true
+	t.mu.Unlock()
+}
+
+// Latest returns the latest published frame and whether it is new since
+// the previous call. The frame stays valid until the next call.
+func (t *TripleBuffer) Latest() (*Frame, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.fresh {
+		return t.front, false
+	}
+	t.front, t.ready = t.ready, t.front
+	t.fresh = false
+	return t.front, true
+}
+
+// Changes from the window RunDecoupled queues for the simulation
+const EDIT_QUEUE = 256
+
+// RunDecoupled is Run with the simulation on its own goroutine, advancing as
+// fast as it can, while this one shows and writes the latest generation it
+// finished. Callbacks run on the simulation goroutine. The window and the
+// stream skip generations they are too slow for instead of holding the
+// simulation up. Painting and single steps in the window are queued for the
+// simulating goroutine, which applies them before its next generation.
+func (game *Game) RunDecoupled(ctx context.Context, out io.Writer, renderer *sdl.Renderer) error {
+	buffers := NewTripleBuffer(game.width, game.height)
+	copyFrame(buffers.Back(), game)
+	buffers.Publish()
+
+	// Everything drawn and written reads this copy of the game, whose grid
+	// is the latest frame
+	view := *game
+	view.nextGrid, view.age, view.nextAge, view.history = nil, nil, nil, nil
+	view.aux, view.nextAux = nil, nil
+	view.callbacks = nil
+	edits := make(chan func(g *Game), EDIT_QUEUE)
+	view.edits = edits
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var paused atomic.Bool
+	paused.Store(game.paused)
+	done := make(chan error, 1)
+	go func() { done <- game.simulate(ctx, buffers, &paused, edits) }()
+
+	simulating := true
+	for !view.quit {
+		select {
+		case <-ctx.Done():
+			if simulating {
+				<-done
+			}
+			return ctx.Err()
+		case err := <-done:
+			simulating = false
+			if err != nil {
+				return err
+			}
+		case sig := <-view.control:
+			view.handleSignal(sig)
+		default:
+		}
+
+		frame, fresh := buffers.Latest()
+		view.grid, view.generation, view.population = frame.grid, frame.generation, frame.population
+		if view.limiter != nil {
+			view.limiter.Wait(view.wait)
+		}
+		if *visual {
+			view.visualize(renderer)
+		}
+		if fresh && !frame.edited {
+			if err := view.writeOutput(out); err != nil {
+				return fmt.Errorf("output: %w", err)
+			}
+			if !*visual {
+				tickFPS() // visualize counts frames otherwise
+			}
+		} else if !*visual || !simulating {
+			view.wait(time.Millisecond)
+		}
+		// Headless, the run is over once the last generation is written
+		if !simulating && !*visual {
+			return nil
+		}
+		paused.Store(view.paused)
+	}
+	cancel()
+	if !simulating {
+		return nil
+	}
+	if err := <-done; err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// simulate advances the game for RunDecoupled, publishing every generation.
+// The changes queued on edits are applied before each one, and published
+// on their own while paused.
+func (game *Game) simulate(ctx context.Context, buffers *TripleBuffer, paused *atomic.Bool, edits <-chan func(g *Game)) error {
+	idleGenerations := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		edited := false
+		for queued := true; queued; {
+			select {
+			case fn := <-edits:
+				fn(game)
+				edited = true
+			default:
+				queued = false
+			}
+		}
+		if paused.Load() && !game.step {
+			if edited {
+				copyFrame(buffers.Back(), game)
+				buffers.Back().edited = true
+				buffers.Publish()
+			}
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		game.step = false
+
+		start := time.Now()
+		game.Update()
+		took := time.Since(start)
+		if game.latency != nil {
+			game.latency.Record(took)
+		}
+		if game.throttle != nil {
+			game.throttle.Wait(took, time.Sleep)
+		}
+		changed := game.SwapAndCount()
+		stop := false
+		for _, fn := range game.callbacks {
+			if err := fn(game, game.Generation()); err != nil {
+				if !errors.Is(err, ErrStop) {
+					return err
+				}
+				stop = true
+				break
+			}
+		}
+		copyFrame(buffers.Back(), game)
+		buffers.Publish()
+		if stop {
+			return nil
+		}
+		if game.paused {
+			return nil // paused by a callback, like -stop-on-extinction
+		}
+
+		if changed == 0 {
+			idleGenerations++
+		} else {
+			idleGenerations = 0
+		}
+		if *idleAfter > 0 && idleGenerations >= *idleAfter {
+			time.Sleep(*stepDelay)
+		}
+		if *stopOnEmpty && game.Live() == 0 {
+			slog.Info("board empty", "generation", game.Generation())
+			return nil
+		}
+	}
+}
+
+func copyFrame(f *Frame, g *Game) {
+	for x := range g.grid {
+		copy(f.grid[x], g.grid[x])
+	}
+	f.generation, f.population = g.generation, g.population
+	f.edited = false
+}
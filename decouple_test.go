@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A writer fills whole frames with its generation number while a fake
+// renderer takes the latest one. Every frame it gets must be uniform and
+// the generations must never go backwards.
+func TestTripleBufferNoTornFrames(t *testing.T) {
+	buffers := NewTripleBuffer(64, 64)
+	const frames = 20000
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for gen := 1; gen <= frames; gen++ {
+			f := buffers.Back()
+			for x := range f.grid {
+				for y := range f.grid[x] {
+					f.grid[x][y] = uint8(gen)
+				}
+			}
+			f.generation = gen
+			buffers.Publish()
+		}
+	}()
+
+	last := 0
+	for last < frames {
+		f, fresh := buffers.Latest()
+		if !fresh {
+			continue
+		}
+		if f.generation < last {
+			t.Fatalf("generation %d after %d", f.generation, last)
+		}
+		last = f.generation
+		for x := range f.grid {
+			for y := range f.grid[x] {
+				if f.grid[x][y] != uint8(f.generation) {
+					t.Fatalf("torn frame: generation %d has %d at %d,%d", f.generation, f.grid[x][y], x, y)
+				}
+			}
+		}
+	}
+	wg.Wait()
+}
+
+// Every frame RunDecoupled writes must be exactly the board of the
+// generation in its header, checked against a game run on its own
+func TestRunDecoupledWritesWholeGenerations(t *testing.T) {
+	defer func(v bool, p Protocol) { *visual, protocol = v, p }(*visual, protocol)
+	*visual, protocol = false, SparsePixelsHeader
+
+	g, _ := NewGame(40, 30, rand.New(rand.NewSource(5)))
+	var out bytes.Buffer
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if err := g.RunDecoupled(ctx, &out, nil); err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+
+	ref, _ := NewGame(40, 30, rand.New(rand.NewSource(5)))
+	stream := out.Bytes()
+	frames, last := 0, -1
+	for len(stream) > 0 {
+		gen := int(binary.LittleEndian.Uint32(stream[1:]))
+		count := int(binary.LittleEndian.Uint32(stream[5:]))
+		cells := stream[9 : 9+4*count]
+		stream = stream[9+4*count+4:]
+		if gen <= last {
+			t.Fatalf("generation %d written after %d", gen, last)
+		}
+		last = gen
+		for ref.Generation() < gen {
+			ref.Update()
+			ref.Swap()
+		}
+
+		board := makeGame(40, 30)
+		for i := 0; i < len(cells); i += 4 {
+			x, y, state := unpackCell(binary.LittleEndian.Uint32(cells[i:]))
+			board.grid[x][y] = state
+		}
+		if !board.Equal(ref) {
+			t.Fatalf("frame of generation %d is not that generation", gen)
+		}
+		frames++
+	}
+	if frames < 2 {
+		t.Fatalf("only %d frames written", frames)
+	}
+}
+
+// Changes and step requests queued while paused reach the simulating game
+func TestSimulateAppliesEdits(t *testing.T) {
+	g := makeGame(10, 10)
+	buffers := NewTripleBuffer(10, 10)
+	var paused atomic.Bool
+	paused.Store(true)
+	edits := make(chan func(g *Game), EDIT_QUEUE)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- g.simulate(ctx, buffers, &paused, edits) }()
+
+	latest := func(want func(f *Frame) bool) *Frame {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if f, fresh := buffers.Latest(); fresh && want(f) {
+				return f
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Fatal("no such frame published")
+		return nil
+	}
+
+	// A blinker painted while paused shows up without a new generation
+	edits <- func(g *Game) {
+		for x := 3; x <= 5; x++ {
+			g.Set(x, 4, BLUE)
+		}
+	}
+	f := latest(func(f *Frame) bool { return f.edited })
+	if f.generation != 0 || f.grid[3][4] != BLUE || f.grid[5][4] != BLUE {
+		t.Fatalf("edit not published: generation %d", f.generation)
+	}
+
+	// A step advances exactly one generation and stays paused
+	edits <- func(g *Game) { g.step = true }
+	f = latest(func(f *Frame) bool { return !f.edited })
+	if f.generation != 1 || f.grid[4][3] != BLUE || f.grid[4][5] != BLUE {
+		t.Fatalf("step gave generation %d", f.generation)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, fresh := buffers.Latest(); fresh {
+		t.Fatal("kept running after the step")
+	}
+
+	cancel()
+	<-done
+}
+
+// A headless run stopped by a callback still writes the generation it
+// stopped at
+func TestRunDecoupledWritesLastGeneration(t *testing.T) {
+	defer func(v bool, p Protocol) { *visual, protocol = v, p }(*visual, protocol)
+	*visual, protocol = false, SparsePixelsHeader
+
+	const LAST = 25
+	g, _ := NewGame(40, 30, rand.New(rand.NewSource(5)))
+	g.OnGeneration(func(g *Game, gen int) error {
+		if gen == LAST {
+			return ErrStop
+		}
+		return nil
+	})
+	var out bytes.Buffer
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := g.RunDecoupled(ctx, &out, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	stream := out.Bytes()
+	last := -1
+	for len(stream) > 0 {
+		last = int(binary.LittleEndian.Uint32(stream[1:]))
+		count := int(binary.LittleEndian.Uint32(stream[5:]))
+		stream = stream[9+4*count+4:]
+	}
+	if last != LAST {
+		t.Errorf("the last frame written is generation %d, want %d", last, LAST)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// outputDeltaCells writes the cells whose state changed since the previous
+// frame, packed like SparsePixels and followed by END_OF_FRAME. The first
+// frame is compared against an all EMPTY grid, so it holds every non-empty cell.
+func (g *Game) outputDeltaCells(w io.Writer) error {
+	prev := g.deltaBase()
+	rowData := make([]byte, 0, 4*g.width)
+	for y := range g.height {
+		rowData = rowData[:0]
+		for x := range g.width {
+			state := g.grid[x][y]
+			if state == prev[x][y] {
+				continue
+			}
+			prev[x][y] = state
+			rowData = binary.LittleEndian.AppendUint32(rowData, packCell(x, y, state))
+		}
+
+		if len(rowData) > 0 {
+			_, err := w.Write(rowData)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	var eof [4]byte
+	binary.LittleEndian.PutUint32(eof[:], END_OF_FRAME)
+	_, err := w.Write(eof[:])
+	return err
+}
+
+// DeltaDecoder rebuilds the frames of a DeltaCells or DeltaBitmap stream
+type DeltaDecoder struct {
+	Width, Height int
+	// Grid holds the last decoded frame, indexed [x][y]
+	Grid [][]uint8
+}
+
+func NewDeltaDecoder(width, height int) *DeltaDecoder {
+	grid := make([][]uint8, width)
+	for x := range grid {
+		grid[x] = make([]uint8, height)
+	}
+	return &DeltaDecoder{Width: width, Height: height, Grid: grid}
+}
+
+// ReadFrame applies the next frame of r to Grid
+func (d *DeltaDecoder) ReadFrame(r io.Reader) error {
+	var cell [4]byte
+	for {
+		if _, err := io.ReadFull(r, cell[:]); err != nil {
+			return err
+		}
+		packed := binary.LittleEndian.Uint32(cell[:])
+		if packed == END_OF_FRAME {
+			return nil
+		}
+
+		x, y, state := unpackCell(packed)
+		if x >= d.Width || y >= d.Height {
+			return fmt.Errorf("delta cell %d,%d is outside the %dx%d grid", x, y, d.Width, d.Height)
+		}
+		if state > MAX_STATE {
+			return fmt.Errorf("delta cell state %d is out of range", state)
+		}
+		d.Grid[x][y] = state
+	}
+}
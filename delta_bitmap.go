@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// outputDeltaBitmap writes one bit per cell in row order, least significant
+// bit first, set where the state changed since the previous frame, then the
+// new state of every set cell in the same order, one byte each. Like
+// DeltaCells the first frame is compared against an all EMPTY grid.
+func (g *Game) outputDeltaBitmap(w io.Writer) error {
+	prev := g.deltaBase()
+	n := g.width * g.height
+	frame := make([]byte, (n+7)/8, (n+7)/8+n/8)
+	for y := range g.height {
+		for x := range g.width {
+			state := g.grid[x][y]
+			if state == prev[x][y] {
+				continue
+			}
+			prev[x][y] = state
+			i := y*g.width + x
+			frame[i/8] |= 1 << (i % 8)
+			frame = append(frame, state)
+		}
+	}
+	_, err := w.Write(frame)
+	return err
+}
+
+// deltaBase returns the last frame written by a delta protocol, allocating
+// an all EMPTY one before the first
+func (g *Game) deltaBase() [][]uint8 {
+	if g.deltaPrev == nil {
+		g.deltaPrev = make([][]uint8, g.width)
+		for x := range g.deltaPrev {
+			g.deltaPrev[x] = make([]uint8, g.height)
+		}
+	}
+	return g.deltaPrev
+}
+
+// ReadBitmapFrame applies the next DeltaBitmap frame of r to Grid
+func (d *DeltaDecoder) ReadBitmapFrame(r io.Reader) error {
+	n := d.Width * d.Height
+	bitmap := make([]byte, (n+7)/8)
+	if _, err := io.ReadFull(r, bitmap); err != nil {
+		return err
+	}
+	changed := 0
+	for _, b := range bitmap {
+		changed += bits.OnesCount8(b)
+	}
+	states := make([]byte, changed)
+	if _, err := io.ReadFull(r, states); err != nil {
+		return err
+	}
+
+	next := 0
+	for i, b := range bitmap {
+		for ; b != 0; b &= b - 1 {
+			cell := i*8 + bits.TrailingZeros8(b)
+			if cell >= n {
+				return fmt.Errorf("delta bitmap marks cell %d of a %dx%d grid", cell, d.Width, d.Height)
+			}
+			if states[next] > MAX_STATE {
+				return fmt.Errorf("delta bitmap state %d is out of range", states[next])
+			}
+			d.Grid[cell%d.Width][cell/d.Width] = states[next]
+			next++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestDeltaBitmapRoundTrip(t *testing.T) {
+	// A width that is not a multiple of 8, so rows share bitmap bytes
+	g, err := newEmptyGame(45, 31, DoubleBuffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.fillSeeded(2, nil)
+	d := NewDeltaDecoder(g.width, g.height)
+	var stream bytes.Buffer
+	for gen := range 20 {
+		if err := g.outputDeltaBitmap(&stream); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.ReadBitmapFrame(&stream); err != nil {
+			t.Fatal(err)
+		}
+		if stream.Len() != 0 {
+			t.Fatalf("generation %d: %d bytes left after the frame", gen, stream.Len())
+		}
+		if !EqualGrid(d.Grid, g.grid) {
+			t.Fatalf("generation %d decoded to a different grid", gen)
+		}
+		g.Update()
+		g.Swap()
+	}
+}
+
+// churn gives the given share of the cells a random state, the same
+// cells for the same frame
+func churn(g *Game, frame int, share float64) {
+	for x := range g.width {
+		for y := range g.height {
+			if float64(cellRand(int64(frame), x, y)%10000) < share*10000 {
+				g.grid[x][y] = uint8(cellRand(int64(-frame), x, y) % (MAX_STATE + 1))
+			}
+		}
+	}
+}
+
+// BenchmarkDeltaSize compares the bytes per frame of DeltaBitmap, DeltaCells
+// and an RLE snapshot as more of the cells change between frames
+func BenchmarkDeltaSize(b *testing.B) {
+	for _, share := range []float64{0.001, 0.01, 0.05, 0.2} {
+		for _, encoder := range []struct {
+			name  string
+			write func(g *Game, w io.Writer) error
+		}{
+			{"delta-bitmap", (*Game).outputDeltaBitmap},
+			{"delta-cells", (*Game).outputDeltaCells},
+			{"rle", (*Game).WriteRLE},
+		} {
+			b.Run(fmt.Sprintf("changed=%g%%/%s", share*100, encoder.name), func(b *testing.B) {
+				g := benchGame(b, 500, 500)
+				var frame bytes.Buffer
+				// The first delta frame holds every cell, it is not counted
+				if err := encoder.write(g, &frame); err != nil {
+					b.Fatal(err)
+				}
+				total := 0
+				b.ResetTimer()
+				for i := range b.N {
+					churn(g, i, share)
+					frame.Reset()
+					if err := encoder.write(g, &frame); err != nil {
+						b.Fatal(err)
+					}
+					total += frame.Len()
+				}
+				b.ReportMetric(float64(total)/float64(b.N), "bytes/frame")
+			})
+		}
+	}
+}
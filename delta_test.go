@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestDeltaCellsRoundTrip(t *testing.T) {
+	g, err := newEmptyGame(50, 40, DoubleBuffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.fillSeeded(1, nil)
+	d := NewDeltaDecoder(g.width, g.height)
+	var stream bytes.Buffer
+	for gen := range 20 {
+		if err := g.outputDeltaCells(&stream); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.ReadFrame(&stream); err != nil {
+			t.Fatal(err)
+		}
+		if stream.Len() != 0 {
+			t.Fatalf("generation %d: %d bytes left after the frame", gen, stream.Len())
+		}
+		if !EqualGrid(d.Grid, g.grid) {
+			t.Fatalf("generation %d decoded to a different grid", gen)
+		}
+		g.Update()
+		g.Swap()
+	}
+}
+
+func TestReadFrameRejectsBadState(t *testing.T) {
+	var frame []byte
+	frame = binary.LittleEndian.AppendUint32(frame, packCell(1, 2, MAX_STATE+1))
+	frame = binary.LittleEndian.AppendUint32(frame, END_OF_FRAME)
+	if err := NewDeltaDecoder(4, 4).ReadFrame(bytes.NewReader(frame)); err == nil {
+		t.Error("a state past MAX_STATE was decoded")
+	}
+}
+
+// BenchmarkDeltaBandwidth compares the bytes per frame of DeltaCells and
+// DenseCells on a sparse board as it evolves
+func BenchmarkDeltaBandwidth(b *testing.B) {
+	defer func(p Protocol) { protocol = p }(protocol)
+	for _, p := range []Protocol{DeltaCells, DenseCells} {
+		b.Run(p.String(), func(b *testing.B) {
+			protocol = p
+			g := sparseBoard(b)
+			var frame bytes.Buffer
+			total := 0
+			b.ResetTimer()
+			for range b.N {
+				frame.Reset()
+				if err := g.writeFrame(&frame); err != nil {
+					b.Fatal(err)
+				}
+				total += frame.Len()
+				g.Update()
+				g.Swap()
+			}
+			b.ReportMetric(float64(total)/float64(b.N), "bytes/frame")
+		})
+	}
+}
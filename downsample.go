@@ -0,0 +1,55 @@
+package main
+
+import "encoding/binary"
+
+// downsampledSize is the size of the grid with a wrapped margin of m cells
+// shrunk by k. Partial blocks at the right and bottom edges still get a pixel.
+func (g *Game) downsampledSize(k, m int) (width, height int) {
+	return (g.width + 2*m + k - 1) / k, (g.height + 2*m + k - 1) / k
+}
+
+// blockColor returns the entry of the color table for the k by k block at
+// bx, by of the grid with a margin of m cells. The block shows its most
+// common state, in the color most of its cells in that state get from
+// colorIndex, so -color modes carry over. Ties go to the lowest state and
+// color, blocks at the edges only count cells inside the image. counts is
+// scratch space of MAX_STATE+1 ints per color.
+func (g *Game) blockColor(bx, by, k, m int, counts []int) int {
+	n := len(counts) / (MAX_STATE + 1)
+	clear(counts)
+	var states [MAX_STATE + 1]int
+	for vx := bx * k; vx < min((bx+1)*k, g.width+2*m); vx++ {
+		for vy := by * k; vy < min((by+1)*k, g.height+2*m); vy++ {
+			x, y, ok := g.resolve(vx-m, vy-m)
+			if ok {
+				state := int(g.grid[x][y])
+				states[state]++
+				counts[state*n+g.colorIndex(x, y)]++
+			}
+		}
+	}
+
+	best := 0
+	for state, count := range states {
+		if count > states[best] {
+			best = state
+		}
+	}
+	colors := counts[best*n : (best+1)*n]
+	index := 0
+	for i, count := range colors {
+		if count > colors[index] {
+			index = i
+		}
+	}
+	return index
+}
+
+// fillDownsampledRow writes row y of the grid with a margin of m shrunk by k
+// into row, 4 bytes per block
+func (g *Game) fillDownsampledRow(row []byte, y, k, m int, colors []uint32, counts []int) {
+	width, _ := g.downsampledSize(k, m)
+	for x := range width {
+		binary.LittleEndian.PutUint32(row[x*4:], colors[g.blockColor(x, y, k, m, counts)])
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"testing"
+)
+
+// blockColors returns the color index of every block of g shrunk by k
+func blockColors(g *Game, k int) []int {
+	counts := make([]int, (MAX_STATE+1)*len(g.colors(&pixelPalette)))
+	width, height := g.downsampledSize(k, 0)
+	var indexes []int
+	for by := range height {
+		for bx := range width {
+			indexes = append(indexes, g.blockColor(bx, by, k, 0, counts))
+		}
+	}
+	return indexes
+}
+
+func TestBlockColorMostCommonState(t *testing.T) {
+	// Three blocks of 2x2 and a partial one of 1x2 at the right edge
+	g := makeGame(7, 2)
+	g.grid[0][0], g.grid[1][0], g.grid[0][1] = BLUE, BLUE, BLUE
+	g.grid[2][0], g.grid[3][1] = ORANGE, ORANGE
+	g.grid[4][0], g.grid[5][0], g.grid[4][1] = DEAD, ORANGE, ORANGE
+	g.grid[6][0], g.grid[6][1] = BLUE, BLUE
+	want := []int{
+		BLUE,
+		EMPTY, // a tie goes to the lowest state
+		ORANGE,
+		BLUE, // the cells past the edge do not count as EMPTY
+	}
+	if got := blockColors(g, 2); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestBlockColorFollowsColorMode(t *testing.T) {
+	defer func(mode ColorMode) { colorMode = mode }(colorMode)
+	colorMode = ColorAge
+
+	// A block of three old blue cells and a young one shows the old shade,
+	// a block of one old and two young ones the young shade
+	g := makeGame(4, 2)
+	g.EnableAge()
+	for x := range 4 {
+		g.grid[x][0], g.grid[x][1] = BLUE, BLUE
+	}
+	g.grid[3][1] = EMPTY
+	g.age[0][0], g.age[1][0], g.age[0][1] = 100, 100, 100
+	g.age[2][0] = 100
+	old, young := MAX_STATE+1+ageStep(100), MAX_STATE+1+ageStep(0)
+	if got := blockColors(g, 2); !slices.Equal(got, []int{old, young}) {
+		t.Errorf("got %v, want the old shade %d then the young one %d", got, old, young)
+	}
+
+	// The dense pixel output uses the same colors
+	defer func(k int, p Protocol) { *downsample, protocol = k, p }(*downsample, protocol)
+	*downsample, protocol = 2, DensePixels
+	var b bytes.Buffer
+	if err := g.writeFrame(&b); err != nil {
+		t.Fatal(err)
+	}
+	colors := g.colors(&pixelPalette)
+	if b.Len() != 8 || binary.LittleEndian.Uint32(b.Bytes()) != colors[old] || binary.LittleEndian.Uint32(b.Bytes()[4:]) != colors[young] {
+		t.Errorf("wrote % x, want the colors %06x and %06x", b.Bytes(), colors[old], colors[young])
+	}
+}
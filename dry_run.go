@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// checkRunFlags validates the flags main only acts on after the window is
+// open, so mistakes in them are caught before anything starts
+func checkRunFlags() error {
+	if *chunkSize < 1 {
+		return fmt.Errorf("-chunk-size must be positive")
+	}
+	if *downsample < 0 {
+		return fmt.Errorf("-downsample cannot be negative")
+	}
+	if *noise < 0 || *noise > 1 {
+		return fmt.Errorf("-noise must be between 0 and 1")
+	}
+	if *fps < 0 || *outputFPS < 0 || *latencyBudget < 0 {
+		return fmt.Errorf("-fps, -output-fps and -latency-budget cannot be negative")
+	}
+	if *statsPath != "" {
+		if _, err := parseTimeUnit(*statsUnit); err != nil {
+			return err
+		}
+	}
+	if *pngDir != "" {
+		if *pngDigits < 1 || *pngStart < 0 {
+			return fmt.Errorf("-png-digits must be positive and -png-start cannot be negative")
+		}
+		if err := checkPNGDir(*pngDir, *force); err != nil {
+			return err
+		}
+	}
+	if *benchmarkRule > 0 && *classic {
+		return fmt.Errorf("-benchmark-rule times the color engine, it cannot be used with -classic")
+	}
+	if *cpuPercent < 0 || *cpuPercent > 100 {
+		return fmt.Errorf("-cpu-percent must be between 0 and 100")
+	}
+	if *decouple {
+		if *listenAddr != "" {
+			return fmt.Errorf("-decouple cannot be used with -listen")
+		}
+		if colorMode == ColorAge || colorMode == ColorDiff || colorMode == ColorAux || *activity || *elderAge > 0 || *neighborHist {
+			return fmt.Errorf("-decouple only draws from the grid, it cannot be used with -color age, diff or aux, -activity, -elder-age or -neighbor-hist")
+		}
+	}
+	if *seek != 0 && *replayPath == "" {
+		return fmt.Errorf("-seek needs -replay")
+	}
+	if *persistence < 0 {
+		return fmt.Errorf("-persistence cannot be negative")
+	}
+	if *startDelay < 0 {
+		return fmt.Errorf("-start-delay cannot be negative")
+	}
+	if *smooth < 0 {
+		return fmt.Errorf("-smooth cannot be negative")
+	}
+	if *smooth > 0 && (*decouple || colorMode != ColorState || *activity || *elderAge > 0) {
+		return fmt.Errorf("-smooth blends the state colors, it cannot be used with -decouple, -color age, diff, neighbors or aux, -activity or -elder-age")
+	}
+	if *gridBufferReuse && (*historyDepth > 0 || colorMode == ColorDiff || *activity || *smooth > 0 || *benchmarkRule > 0) {
+		return fmt.Errorf("-grid-buffer-reuse keeps no previous generation, it cannot be used with -history, -color diff, -activity, -smooth or -benchmark-rule")
+	}
+	if *fifoPath != "" {
+		if *listenAddr != "" || *decouple || *outputBuffer > 0 {
+			return fmt.Errorf("-fifo cannot be used with -listen, -decouple or -output-buffer")
+		}
+		if protocol == Off {
+			return fmt.Errorf("-fifo needs a -protocol to write")
+		}
+	}
+	if *teeSinks != "" {
+		if *listenAddr != "" {
+			return fmt.Errorf("-tee cannot be used with -listen, the client picks the protocol")
+		}
+		if protocol == Off {
+			return fmt.Errorf("-tee needs a -protocol to write")
+		}
+	}
+	if *outputBuffer < 0 {
+		return fmt.Errorf("-output-buffer cannot be negative")
+	}
+	if *outputBuffer > 0 && *listenAddr != "" {
+		return fmt.Errorf("-output-buffer cannot be used with -listen, queued frames could reach the next client")
+	}
+	if *snapshotEvery < 0 {
+		return fmt.Errorf("-snapshot-every cannot be negative")
+	}
+	if *snapshotEvery > 0 {
+		if *snapshotKeep < 1 {
+			return fmt.Errorf("-snapshot-keep must be at least 1")
+		}
+		if info, err := os.Stat(*snapshotDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("-snapshot-dir %s is not a directory", *snapshotDir)
+		}
+	}
+	for name, addr := range map[string]string{"metrics": *metricsAddr, "listen": *listenAddr} {
+		if addr == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("bad -%s address: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// writeSummary describes the run the flags set up, for -dry-run
+func writeSummary(w io.Writer, game *Game) {
+	start := "random cells, " + *initPattern
+	switch {
+	case *resume != "":
+		start = fmt.Sprintf("snapshot %s at generation %d", *resume, game.Generation())
+	case *patternPath != "":
+		start = "pattern " + *patternPath
+	case *presetName != "":
+		start = "preset " + *presetName
+	case *soup != "":
+		start = "soup"
+	case *seedImage != "":
+		start = "image " + *seedImage
+	}
+
+	fmt.Fprintf(w, "Grid:         %dx%d, edge %s (wrap x %t, wrap y %t), %s neighborhood\n",
+		game.width, game.height, *edgeFlag, *wrapX, *wrapY, *neighborhoodFlag)
+	fmt.Fprintf(w, "Rule:         %s, %s engine\n", game.rule, game.engineName())
+	if !game.active.Empty() {
+		fmt.Fprintf(w, "Active:       %dx%d cells at %d,%d, the rest frozen\n",
+			game.active.Dx(), game.active.Dy(), game.active.Min.X, game.active.Min.Y)
+	}
+	fmt.Fprintf(w, "Start:        %s\n", start)
+	fmt.Fprintf(w, "Protocol:     %s\n", protocol)
+	if *visual {
+		fmt.Fprintf(w, "Window:       %s render, zoom %d, %s palette\n", *renderFlag, *zoom, palettePresets[palettePreset].name)
+	} else {
+		fmt.Fprintln(w, "Window:       none")
+	}
+	if *fps > 0 {
+		fmt.Fprintf(w, "Pace:         %g generations per second\n", *fps)
+	}
+	if *outputFPS > 0 {
+		fmt.Fprintf(w, "Output pace:  %g frames per second\n", *outputFPS)
+	}
+	if *snapshotEvery > 0 {
+		fmt.Fprintf(w, "Snapshots:    every %d generations to %s, keeping %d\n", *snapshotEvery, *snapshotDir, *snapshotKeep)
+	}
+	if *latencyBudget > 0 {
+		fmt.Fprintf(w, "Latency:      warn over %v\n", time.Duration(*latencyBudget*float64(time.Millisecond)))
+	}
+}
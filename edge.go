@@ -0,0 +1,85 @@
+package main
+
+import "fmt"
+
+// EdgeMode says per axis whether leaving one edge enters the opposite one,
+// or whether the grid is mirrored there. Everything past an edge that does
+// neither is EMPTY.
+type EdgeMode int
+
+const (
+	WrapX EdgeMode = 1 << iota
+	WrapY
+	ReflectX
+	ReflectY
+
+	Bounded    EdgeMode = 0
+	Toroidal            = WrapX | WrapY
+	Reflecting          = ReflectX | ReflectY
+)
+
+func parseEdgeMode(name string) (EdgeMode, error) {
+	switch name {
+	case "torus":
+		return Toroidal, nil
+	case "dead":
+		return Bounded, nil
+	case "reflect":
+		return Reflecting, nil
+	}
+	return Toroidal, fmt.Errorf("unknown edge mode %q, expected torus, dead or reflect", name)
+}
+
+// resolve maps any coordinates to the cell they refer to under the edge mode.
+// A reflecting edge mirrors the grid about the edge line, so x = -1 refers
+// to x = 0 and x = width to x = width-1. ok is false when they fall off an
+// edge that neither wraps nor reflects.
+func (g *Game) resolve(x, y int) (nx, ny int, ok bool) {
+	if x >= 0 && x < g.width && y >= 0 && y < g.height {
+		return x, y, true
+	}
+	return g.resolveOutside(x, y)
+}
+
+// resolveOutside is resolve for coordinates known to be off the grid
+func (g *Game) resolveOutside(x, y int) (nx, ny int, ok bool) {
+	if x < 0 || x >= g.width {
+		switch {
+		case g.edge&WrapX != 0:
+			x = ((x % g.width) + g.width) % g.width
+		case g.edge&ReflectX != 0:
+			x = reflect(x, g.width)
+		default:
+			return 0, 0, false
+		}
+	}
+	if y < 0 || y >= g.height {
+		switch {
+		case g.edge&WrapY != 0:
+			y = ((y % g.height) + g.height) % g.height
+		case g.edge&ReflectY != 0:
+			y = reflect(y, g.height)
+		default:
+			return 0, 0, false
+		}
+	}
+	return x, y, true
+}
+
+// reflect folds i back into 0..size-1 as if the row were mirrored at both ends
+func reflect(i, size int) int {
+	i = ((i % (2 * size)) + 2*size) % (2 * size)
+	if i >= size {
+		i = 2*size - 1 - i
+	}
+	return i
+}
+
+// resolvePlaced is resolve for putting cells on the grid: past a reflecting
+// edge they are dropped rather than mirrored onto cells inside
+func (g *Game) resolvePlaced(x, y int) (nx, ny int, ok bool) {
+	if (g.edge&ReflectX != 0 && (x < 0 || x >= g.width)) || (g.edge&ReflectY != 0 && (y < 0 || y >= g.height)) {
+		return 0, 0, false
+	}
+	return g.resolve(x, y)
+}
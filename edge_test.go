@@ -0,0 +1,82 @@
+package main
+
+import "testing"
+
+func TestResolveCylinder(t *testing.T) {
+	g := makeGame(5, 4)
+	for _, c := range []struct {
+		edge   EdgeMode
+		x, y   int
+		nx, ny int
+		ok     bool
+	}{
+		{WrapX, -1, 2, 4, 2, true},
+		{WrapX, 5, 2, 0, 2, true},
+		{WrapX, 2, -1, 0, 0, false},
+		{WrapX, 2, 4, 0, 0, false},
+		{WrapY, 1, 4, 1, 0, true},
+		{WrapY, 1, -1, 1, 3, true},
+		{WrapY, -1, 1, 0, 0, false},
+		{WrapY, 5, 1, 0, 0, false},
+		// A corner cell past both edges is only there when both wrap
+		{WrapX, -1, -1, 0, 0, false},
+		{Toroidal, -1, -1, 4, 3, true},
+	} {
+		g.edge = c.edge
+		nx, ny, ok := g.resolve(c.x, c.y)
+		if ok != c.ok || (ok && (nx != c.nx || ny != c.ny)) {
+			t.Errorf("edge %d: %d,%d resolved to %d,%d %v, want %d,%d %v", c.edge, c.x, c.y, nx, ny, ok, c.nx, c.ny, c.ok)
+		}
+	}
+}
+
+func TestCylinderNeighbors(t *testing.T) {
+	// Cells on the left edge count the right column but not the bottom row
+	g := makeGame(5, 4)
+	g.edge = WrapX
+	g.grid[4][1] = BLUE
+	g.grid[0][3] = ORANGE
+	if blue, orange := g.CountNeighbors(0, 0); blue != 1 || orange != 0 {
+		t.Errorf("wrapping x: %d blue and %d orange neighbors, want 1 and 0", blue, orange)
+	}
+	g.edge = WrapY
+	if blue, orange := g.CountNeighbors(0, 0); blue != 0 || orange != 1 {
+		t.Errorf("wrapping y: %d blue and %d orange neighbors, want 0 and 1", blue, orange)
+	}
+}
+
+func TestResolveReflecting(t *testing.T) {
+	g := makeGame(4, 3)
+	g.edge = Reflecting
+	for _, c := range []struct{ x, y, nx, ny int }{
+		{-1, 0, 0, 0},
+		{4, 3, 3, 2},
+		{-2, -1, 1, 0},
+		// Far off the grid the mirrored copies repeat
+		{-5, 0, 3, 0},
+		{8, 6, 0, 0},
+	} {
+		if nx, ny, ok := g.resolve(c.x, c.y); !ok || nx != c.nx || ny != c.ny {
+			t.Errorf("%d,%d resolved to %d,%d %v, want %d,%d", c.x, c.y, nx, ny, ok, c.nx, c.ny)
+		}
+	}
+	if _, _, ok := g.resolvePlaced(-1, 0); ok {
+		t.Error("a cell placed past a reflecting edge landed on the grid")
+	}
+}
+
+func TestReflectingCorner(t *testing.T) {
+	// A corner cell is its own neighbor three times over, across both edges
+	// and diagonally, and its two neighbors along the edges count twice
+	g := makeGame(4, 3)
+	g.edge = Reflecting
+	g.grid[0][0] = BLUE
+	if blue, _ := g.CountNeighbors(0, 0); blue != 3 {
+		t.Errorf("corner cell counts itself %d times, want 3", blue)
+	}
+	g.grid[0][0] = EMPTY
+	g.grid[1][0] = ORANGE
+	if _, orange := g.CountNeighbors(0, 0); orange != 2 {
+		t.Errorf("neighbor along the top edge counted %d times, want 2", orange)
+	}
+}
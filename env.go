@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Prefix of the environment variables that stand in for flags
+const ENV_PREFIX = "GOLIFE_"
+
+// envName is the environment variable of a flag, like GOLIFE_CHUNK_SIZE for -chunk-size
+func envName(flagName string) string {
+	return ENV_PREFIX + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// givenFlags returns the names of the flags of fs set so far, after Parse
+// the ones given on the command line
+func givenFlags(fs *flag.FlagSet) map[string]bool {
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	return given
+}
+
+// applyEnv sets every flag of fs not in given from its environment variable
+// when that is set, even to an empty value, and adds it to given. Together
+// with loadScene skipping given flags the command line wins over the
+// environment, which wins over a -scene file.
+func applyEnv(fs *flag.FlagSet, given map[string]bool) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		value, ok := os.LookupEnv(envName(f.Name))
+		if !ok || err != nil || given[f.Name] {
+			return
+		}
+		if setErr := fs.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("%s: %w", envName(f.Name), setErr)
+			return
+		}
+		given[f.Name] = true
+	})
+	return err
+}
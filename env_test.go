@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	for name, want := range map[string]string{
+		"width":      "GOLIFE_WIDTH",
+		"output-fps": "GOLIFE_OUTPUT_FPS",
+		"chunk-size": "GOLIFE_CHUNK_SIZE",
+	} {
+		if got := envName(name); got != want {
+			t.Errorf("-%s reads %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	fs := flag.NewFlagSet("golife", flag.ContinueOnError)
+	width := fs.Int("width", 1000, "")
+	height := fs.Int("height", 1000, "")
+	fps := fs.Float64("fps", 0, "")
+	title := fs.String("title", "golife", "")
+	depth := fs.Int("depth", 3, "")
+	t.Setenv("GOLIFE_WIDTH", "123")
+	t.Setenv("GOLIFE_HEIGHT", "77")
+	t.Setenv("GOLIFE_FPS", "12.5")
+	t.Setenv("GOLIFE_TITLE", "")
+
+	if err := fs.Parse([]string{"-width", "50"}); err != nil {
+		t.Fatal(err)
+	}
+	given := givenFlags(fs)
+	if err := applyEnv(fs, given); err != nil {
+		t.Fatal(err)
+	}
+	// The command line wins, an empty variable still counts as set, an
+	// unset one leaves the default
+	if *width != 50 || *height != 77 || *fps != 12.5 || *title != "" || *depth != 3 {
+		t.Errorf("got width %d, height %d, fps %v, title %q and depth %d", *width, *height, *fps, *title, *depth)
+	}
+	// Only the flags a scene file may no longer change
+	for name, want := range map[string]bool{"width": true, "height": true, "fps": true, "title": true, "depth": false} {
+		if given[name] != want {
+			t.Errorf("-%s given %v, want %v", name, given[name], want)
+		}
+	}
+}
+
+func TestApplyEnvBadValue(t *testing.T) {
+	fs := flag.NewFlagSet("golife", flag.ContinueOnError)
+	fs.Float64("output-fps", 0, "")
+	t.Setenv("GOLIFE_OUTPUT_FPS", "fast")
+	err := applyEnv(fs, map[string]bool{})
+	if err == nil || !strings.Contains(err.Error(), "GOLIFE_OUTPUT_FPS") {
+		t.Errorf("got %v, want an error naming the variable", err)
+	}
+}
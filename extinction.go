@@ -0,0 +1,38 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// StopOnExtinction returns a GenerationFunc that ends the run once BLUE or
+// ORANGE has died out, logging which one and when. Only a color that was on
+// the board of game or appeared later can go extinct. With a window the game
+// is paused instead, so the final board stays on screen.
+func StopOnExtinction(game *Game) GenerationFunc {
+	var seen [MAX_STATE + 1]bool
+	for state, n := range game.population {
+		seen[state] = n > 0
+	}
+	stopped := false
+	return func(g *Game, gen int) error {
+		var extinct []string
+		for _, state := range []int{BLUE, ORANGE} {
+			if g.population[state] > 0 {
+				seen[state] = true
+			} else if seen[state] {
+				extinct = append(extinct, stateNames[state])
+			}
+		}
+		if len(extinct) == 0 || stopped {
+			return nil
+		}
+		slog.Info("extinct", "states", strings.Join(extinct, ","), "generation", gen)
+		if !*visual {
+			return ErrStop
+		}
+		stopped = true
+		g.paused = true
+		return nil
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"io"
+	"testing"
+)
+
+func TestStopOnExtinction(t *testing.T) {
+	defer func(v bool) { *visual = v }(*visual)
+	*visual = false
+
+	// A blue block lives on, the lone orange cell dies in the first generation
+	g := makeGame(20, 20)
+	for _, cell := range [][2]int{{5, 5}, {5, 6}, {6, 5}, {6, 6}} {
+		g.grid[cell[0]][cell[1]] = BLUE
+	}
+	g.grid[15][15] = ORANGE
+	g.countPopulation()
+
+	stop := StopOnExtinction(g)
+	stoppedAt := 0
+	g.OnGeneration(func(g *Game, gen int) error {
+		err := stop(g, gen)
+		if err != nil {
+			stoppedAt = gen
+		}
+		return err
+	})
+	if err := g.Run(context.Background(), io.Discard, nil); err != nil {
+		t.Fatal(err)
+	}
+	if stoppedAt != 1 || g.population[ORANGE] != 0 || g.population[BLUE] != 4 {
+		t.Errorf("stopped at generation %d with %d blue and %d orange", stoppedAt, g.population[BLUE], g.population[ORANGE])
+	}
+}
+
+func TestStopOnExtinctionNeedsTheColorSeen(t *testing.T) {
+	defer func(v bool) { *visual = v }(*visual)
+	*visual = false
+
+	// Orange never was on the board, so it cannot die out
+	g := makeGame(5, 5)
+	g.grid[1][1] = BLUE
+	g.countPopulation()
+	stop := StopOnExtinction(g)
+	if err := stop(g, 1); err != nil {
+		t.Fatalf("stopped for a color never seen: %v", err)
+	}
+
+	// Once it shows up it can
+	g.grid[3][3] = ORANGE
+	g.countPopulation()
+	if err := stop(g, 2); err != nil {
+		t.Fatal(err)
+	}
+	g.grid[3][3] = EMPTY
+	g.countPopulation()
+	if err := stop(g, 3); err != ErrStop {
+		t.Fatalf("orange died out, got %v", err)
+	}
+}
+
+func TestStopOnExtinctionPausesWindow(t *testing.T) {
+	defer func(v bool) { *visual = v }(*visual)
+	*visual = true
+
+	g := makeGame(5, 5)
+	g.grid[1][1] = BLUE
+	g.countPopulation()
+	stop := StopOnExtinction(g)
+	g.grid[1][1] = EMPTY
+	g.countPopulation()
+	if err := stop(g, 1); err != nil || !g.paused {
+		t.Fatalf("with a window the game should pause, got %v and paused %v", err, g.paused)
+	}
+
+	// Unpaused again it keeps running
+	g.paused = false
+	if err := stop(g, 2); err != nil || g.paused {
+		t.Fatalf("paused a second time: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// FIFOWriter writes the stream to a named pipe. Opening a pipe for writing
+// blocks until a reader opens it, and writing fails once the reader closes
+// it, so a lost reader is waited for again in the background while frames
+// are dropped, the way StreamServer waits for its next client.
+type FIFOWriter struct {
+	path string
+	f    *os.File
+
+	// Opened in the background, waiting for the next frame
+	next chan *os.File
+}
+
+// OpenFIFO creates the named pipe at path unless it exists and waits until
+// a reader opens it or ctx is done
+func OpenFIFO(ctx context.Context, path string) (*FIFOWriter, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		err = makeFIFO(path)
+	} else if err == nil && info.Mode()&os.ModeNamedPipe == 0 {
+		err = fmt.Errorf("%s exists and is not a named pipe", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w := &FIFOWriter{path: path, next: make(chan *os.File, 1)}
+	slog.Info("waiting for a reader", "fifo", path)
+	go w.open()
+	select {
+	case w.f = <-w.next:
+		return w, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// open waits for a reader and hands the pipe to the next switchReader
+func (w *FIFOWriter) open() {
+	f, err := os.OpenFile(w.path, os.O_WRONLY, 0)
+	if err != nil {
+		slog.Warn("could not reopen the fifo, frames are dropped", "fifo", w.path, "err", err)
+		return
+	}
+	w.next <- f
+}
+
+// Write sends p to the reader. A failed write drops the reader and starts
+// waiting for a new one, frames are discarded in the meantime so the game
+// keeps running.
+func (w *FIFOWriter) Write(p []byte) (int, error) {
+	if w.f == nil {
+		return len(p), nil
+	}
+	if _, err := w.f.Write(p); err != nil {
+		slog.Warn("lost the reader, waiting for the next one", "fifo", w.path, "err", err)
+		w.f.Close()
+		w.f = nil
+		go w.open()
+	}
+	return len(p), nil
+}
+
+// switchReader is a GenerationFunc that hands the stream to a reader that
+// opened the pipe in the background. It runs between frames, so the reader
+// starts with a whole one, and for the delta protocols one holding every
+// non-empty cell.
+func (w *FIFOWriter) switchReader(g *Game, gen int) error {
+	select {
+	case f := <-w.next:
+		slog.Info("streaming to a new reader", "fifo", w.path, "generation", gen)
+		w.f = f
+		g.deltaPrev = nil
+	default:
+	}
+	return nil
+}
+
+func (w *FIFOWriter) Close() error {
+	if w.f == nil {
+		return nil
+	}
+	return w.f.Close()
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "errors"
+
+// There are no named pipes to create here
+func makeFIFO(path string) error {
+	return errors.New("-fifo needs a system with named pipes")
+}
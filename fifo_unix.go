@@ -0,0 +1,10 @@
+//go:build unix
+
+package main
+
+import "syscall"
+
+// makeFIFO creates a named pipe readable and writable by everyone the umask allows
+func makeFIFO(path string) error {
+	return syscall.Mkfifo(path, 0o666)
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"flag"
+	"time"
+)
+
+// Command line options
+var (
+	width  = flag.Int("width", gridWidth, "grid width in cells, grows to fit -pattern unless set")
+	height = flag.Int("height", gridHeight, "grid height in cells, grows to fit -pattern unless set")
+
+	scene = flag.String("scene", "", "JSON file of flag values, like {\"rule\": \"B3/S23\", \"width\": 200}, flags given here win")
+
+	ruleFlag = flag.String("rule", DEFAULT_RULE,
+		"birth and survival neighbor counts, like B3/S23, overrides the rule of a -pattern file")
+
+	classic = flag.Bool("classic", false,
+		"two states only: live cells die straight to EMPTY and every birth is BLUE, C switches it in the window")
+
+	visual = flag.Bool("visual", VISUAL_OUT, "show the board in a window")
+
+	protocolFlag = flag.String("protocol", PROTOCOL.String(),
+		"stream written to stdout: off, dense-cells, sparse-pixels, dense-pixels, sparse-header, delta-cells, dense-pixels-16 or delta-bitmap")
+
+	listenAddr = flag.String("listen", "",
+		"stream to a TCP client on this address instead of stdout, the client's first byte picks the protocol")
+
+	fifoPath = flag.String("fifo", "",
+		"write the stream to this named pipe instead of stdout, created if missing and reopened when the reader goes away")
+
+	teeSinks = flag.String("tee", "",
+		"also write the stream to these comma separated sinks, files or tcp:host:port to connect to, dropping any that fails")
+
+	headlessFallback = flag.Bool("headless-fallback", false,
+		"keep running without a window when one cannot be opened")
+
+	pauseOnBlur = flag.Bool("pause-on-blur", false, "pause while the window does not have the focus")
+
+	// Only BLUE and ORANGE cells count as alive here, a board holding
+	// nothing but decaying cells is treated as empty.
+	stopOnEmpty = flag.Bool("stop-on-empty", false,
+		"stop once no BLUE or ORANGE cells are left (pauses when visualizing)")
+	stopOnExtinction = flag.Bool("stop-on-extinction", false,
+		"stop once BLUE or ORANGE has died out, even if the other lives on (pauses when visualizing)")
+
+	// Rows are never split, so a chunk smaller than a row writes one row at a time
+	chunkSize = flag.Int("chunk-size", 64*1024,
+		"bytes of dense pixel rows to buffer before each write")
+
+	downsample = flag.Int("downsample", 1,
+		"shrink dense pixel output by this factor, each pixel showing the most common state of a block")
+
+	margin = flag.Int("margin", 0,
+		"surround dense pixel output with this many cells wrapped around from the opposite edges, torus only")
+
+	maxMem = flag.Int("max-mem", 0, "refuse to start when the estimated memory is over this many MiB, 0 for no limit")
+
+	// The result of Update does not depend on this, only the speed does
+	workers = flag.Int("workers", 0, "goroutines used per generation, 0 for one per CPU")
+
+	activeRect = flag.String("active-rect", "",
+		"x,y,w,h of the only cells computed each generation, the ones outside stay as they are, the whole grid by default")
+
+	gridBufferReuse = flag.Bool("grid-buffer-reuse", false,
+		"update the grid in place with a rolling column buffer instead of a second full grid, about half the grid memory for a little speed")
+
+	scheduleFlag = flag.String("schedule", "tiles",
+		"how workers share the grid: tiles (each takes small ranges of columns from a shared queue as it finishes) or static (one equal range each)")
+
+	cpuPercent = flag.Float64("cpu-percent", 0,
+		"sleep between generations so Update uses at most this percent of all cores on average, 0 for no limit")
+
+	edgeFlag = flag.String("edge", "torus",
+		"what lies past the edges: torus (wrap around), dead (always EMPTY) or reflect (the grid mirrored)")
+	wrapX = flag.Bool("wrap-x", true, "wrap around the left and right edges, turn off for a plane or a cylinder")
+	wrapY = flag.Bool("wrap-y", true, "wrap around the top and bottom edges, turn off for a plane or a cylinder")
+
+	neighborhoodFlag = flag.String("neighborhood", "moore",
+		"cells counted as neighbors: moore (all 8) or vonneumann (the 4 orthogonal ones)")
+
+	decouple = flag.Bool("decouple", false,
+		"advance the simulation on its own goroutine as fast as it can, showing and writing the latest generation it finished")
+
+	fps = flag.Float64("fps", 0, "generations shown and written per second, 0 for as fast as possible")
+
+	outputFPS = flag.Float64("output-fps", 0,
+		"frames written per second whatever the simulation speed, repeating or dropping generations, 0 to write each once")
+
+	outputBuffer = flag.Int("output-buffer", 0,
+		"frames of output queued for a slow reader before new ones are dropped, 0 to write each frame before going on")
+
+	latencyBudget = flag.Float64("latency-budget", 0,
+		"warn when Update takes longer than this many milliseconds, checked every 100 generations, 0 to not time it")
+
+	dryRun = flag.Bool("dry-run", false, "check the options, print what the run would be and exit")
+
+	selfTest = flag.Bool("selftest", false,
+		"check that a blinker, a toad and a pulsar oscillate with the right period in the classic engine, print PASS or FAIL and exit")
+
+	benchmarkRule = flag.Int("benchmark-rule", 0,
+		"time this many generations of the branchy and the table driven rule evaluation, check they agree and exit")
+
+	dumpRule = flag.Bool("dump-rule", false,
+		"print the next state of every state for every neighbor count under the current settings and exit")
+
+	enemyMargin = flag.Int("enemy-margin", 0,
+		"a live cell dies when it has this many more neighbors of the other color than of its own, 0 to treat colors alike")
+
+	noise = flag.Float64("noise", 0, "fraction of all cells turned into random BLUE or ORANGE cells after every generation")
+
+	birthBias = flag.Float64("birth-bias", 0,
+		"blue neighbors must outnumber orange ones by more than this for a BLUE birth, negative values favor BLUE")
+
+	idleAfter = flag.Int("idle-after", 10,
+		"generations without any changed cell before each step is delayed, 0 to never delay")
+	stepDelay = flag.Duration("step-delay", 50*time.Millisecond,
+		"pause between generations once the board is idle, window events end it early")
+
+	startDelay = flag.Duration("start-delay", 0,
+		"show the starting board for this long before the first generation, 0 to start at once")
+	startCountdown = flag.Bool("start-countdown", false,
+		"draw a bar along the bottom of the window that runs out with -start-delay")
+
+	patternPath = flag.String("pattern", "", "start from this RLE file, or a CSV of states when it ends in .csv, on an empty board instead of random cells")
+	presetName  = flag.String("preset", "", "start from a built in pattern: glider, lwss, blinker, toad, pulsar or gosper")
+	soup        = flag.String("soup", "", "start from a 16x16 soup given as 32 bytes of base64, - reads it from stdin")
+	place       = flag.String("place", "", "x,y of the top left corner of the pattern, centered by default")
+	orient      = flag.String("orient", "", "turn the pattern with r90, r180 or r270 (clockwise) or mirror it with flipx or flipy")
+
+	metricsAddr = flag.String("metrics", "", "serve generation, FPS, population and uptime as JSON on this address, like :8080")
+
+	statsPath    = flag.String("stats", "", "write the population of every generation to this CSV file")
+	statsUnit    = flag.String("stats-unit", "ms", "unit of the times in the stats file: ns or ms")
+	statsGenTime = flag.Bool("stats-gen-time", false, "add the time each generation took to the stats file")
+
+	snapshotEvery = flag.Int("snapshot-every", 0, "save the board as RLE every this many generations, 0 for never")
+	snapshotDir   = flag.String("snapshot-dir", ".", "directory the snapshots are saved in")
+	snapshotKeep  = flag.Int("snapshot-keep", 5, "number of snapshots kept, older ones are deleted")
+	resume        = flag.String("resume", "", "continue from a snapshot saved by -snapshot-every instead of a new board")
+
+	replayPath = flag.String("replay", "",
+		"read a recorded dense-cells stream of a -width x -height grid, write the -seek frame in -protocol (RLE when off) and exit")
+	seek = flag.Int("seek", 0, "frame of the -replay recording to write, counted from 0")
+
+	pngDir    = flag.String("png-dir", "", "write every generation as a numbered PNG into this directory, which must be empty unless -force is set")
+	pngDigits = flag.Int("png-digits", 6, "digits the -png-dir frame numbers are zero padded to")
+	pngStart  = flag.Int("png-start", 0, "number of the first -png-dir frame")
+	force     = flag.Bool("force", false, "write -png-dir frames into a directory that already holds files")
+
+	svgPath = flag.String("svg", "", "save the last generation as an SVG image to this file when the run ends")
+	csvPath = flag.String("csv", "", "save the state of every cell of the last generation as CSV to this file when the run ends")
+
+	pins    = flag.String("pin", "", "cells that always stay BLUE, as x,y pairs separated by ;")
+	pinFile = flag.String("pin-file", "", "file of cells that always stay BLUE, one x,y per line")
+
+	seedImage = flag.String("seed-image", "", "start from a PNG or JPEG scaled to the grid instead of random cells")
+	seedDark  = flag.Float64("seed-dark", 0.35, "seed image pixels darker than this (0-1) become BLUE")
+	seedLight = flag.Float64("seed-light", 0.8, "seed image pixels lighter than this (0-1) are never ORANGE")
+	seedWarm  = flag.Float64("seed-warm", 0.2, "seed image pixels whose red exceeds blue by more than this (0-1) become ORANGE")
+
+	compare = flag.String("compare", "",
+		"run two rules side by side from the same random board, like B3/S23,B36/S23")
+
+	trackShips = flag.Bool("track-ships", false,
+		"follow connected groups of cells every generation and log the ones moving in a straight line, costs a labeling pass per generation")
+
+	search            = flag.Int("search", 0, "run this many random 16x16 soups without a window and report the ones that end oscillating or moving")
+	searchGenerations = flag.Int("search-gens", 5000, "generations a -search soup may run before it is given up on")
+	searchSave        = flag.String("search-save", "", "directory -search saves the oscillating or moving boards to as RLE")
+
+	boards     = flag.Int("boards", 0, "run this many independent random boards tiled in one window")
+	boardRules = flag.String("board-rules", "", "rules of the -boards, comma separated and reused from the start when short, -rule by default")
+	boardSeeds = flag.String("board-seeds", "", "seeds of the -boards, one per board comma separated, -seed plus the board index by default")
+
+	logLevel = flag.String("log-level", "info", "least important messages logged to stderr: debug, info (FPS and progress), warn or error")
+
+	seed = flag.Int64("seed", 0, "seed for the initial board, 0 for a random one")
+
+	initPattern   = flag.String("init-pattern", "noise", "random starting board: noise (every cell alike), clustered (discs of one color) or stripes (bands of alternating color)")
+	initDensity   = flag.Float64("init-density", 0.5, "chance that a cell in a clustered disc or a stripe is live")
+	clusterRadius = flag.Int("cluster-radius", 8, "largest radius of the clustered discs in cells")
+	stripeWidth   = flag.Int("stripe-width", 16, "width of each stripe in cells")
+
+	renderFlag = flag.String("render", "points",
+		"how the window is drawn: points (DrawPoints per color), texture (streaming texture upload), squares or circles")
+	drawBatch = flag.Int("draw-batch", 1<<16, "most points or rectangles drawn in one SDL call, 0 for no limit")
+	zoom      = flag.Int("zoom", 1, "window pixels per cell, points are drawn as squares above 1")
+
+	brushSize  = flag.Int("brush-size", 1, "cells across the mouse brush, [ and ] change it in the window")
+	brushShape = flag.String("brush", "square", "shape of the mouse brush: square or circle, B switches it")
+
+	persistence = flag.Float64("persistence", 0,
+		"milliseconds a grey trail lingers in the window where a cell was live, fading on the clock whatever the generation rate, 0 for none")
+
+	smooth = flag.Int("smooth", 0,
+		"frames drawn between generations fading each cell from its previous color to its new one, spread over the -fps interval, 0 for none")
+
+	tilePreview = flag.Bool("tile-preview", false, "draw the board 3x3 times at a third of the size to show how it wraps, T switches it")
+
+	minimapSize   = flag.Int("minimap", 0, "show the whole board this many pixels across in a corner of the window, M hides it, 0 for none")
+	minimapCorner = flag.String("minimap-corner", "top-right", "corner of the minimap: top-left, top-right, bottom-left or bottom-right")
+
+	neighborHist = flag.Bool("neighbor-hist", false,
+		"draw a bar chart of how many cells have 0 to 8 live neighbors in a bottom corner of the window, H hides it")
+
+	// Age tracking costs two extra uint16 grids, so it is only allocated for -color age
+	colorFlag = flag.String("color", "state",
+		"what cell colors show: state, age (live cells shaded by generations survived), diff (cells changed by the last generation highlighted), neighbors (every cell by its live neighbor count) or aux (every cell by generations since it last changed)")
+
+	elderAge = flag.Int("elder-age", 0,
+		"draw BLUE and ORANGE cells that survived this many generations unchanged in a lighter shade, 0 for never")
+
+	activity          = flag.Bool("activity", false, "draw the cells the last generation changed in a highlight color for one frame")
+	activityColorFlag = flag.String("activity-color", "", "highlight color of -activity as RRGGBB, the palette preset picks one by default")
+
+	decayFadeIn = flag.Bool("decay-fade-in", false,
+		"draw decaying cells getting darker instead of fading out, in the window and in pixel output")
+
+	palettePresetFlag = flag.String("palette-preset", "default",
+		"colors to start with: default, high-contrast or colorblind, P cycles them in the window")
+
+	historyDepth = flag.Int("history", 0, "previous generations to keep in memory")
+)
+
+// flagSet reports whether a flag was given on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -1,23 +1,32 @@
 package main
 
 import (
-	"fmt"
-	"os"
+	"log/slog"
+	"math"
+	"sync/atomic"
 	"time"
 )
 
-// Helper function
 // Package level variables for FPS counter
 var (
 	fpsCounter     int
 	fpsLastPrint   time.Time
 	fpsInitialized bool
+	// Frames counted in the last full second, read by callbacks that may
+	// run on another goroutine
+	fpsLast atomic.Int64
+	// Frames written by an OutputPacer, counted the same way
+	fpsOutputCounter int
+	fpsOutputLast    int
+	// Replaced by tests that need a fake clock
+	fpsClock = time.Now
 )
 
-func printFPS() {
+// tickFPS counts a frame and reports the frame rate once a second has passed
+func tickFPS() (fps int, ok bool) {
 	// Initialize on first call
 	if !fpsInitialized {
-		fpsLastPrint = time.Now()
+		fpsLastPrint = fpsClock()
 		fpsInitialized = true
 	}
 
@@ -25,10 +34,51 @@ func printFPS() {
 	fpsCounter++
 
 	// Check if a second has passed
-	now := time.Now()
+	now := fpsClock()
 	if now.Sub(fpsLastPrint).Seconds() >= 1.0 {
-		fmt.Fprintf(os.Stderr, "FPS: %d\n", fpsCounter)
+		fpsLast.Store(int64(fpsCounter))
 		fpsCounter = 0
+		fpsOutputLast = fpsOutputCounter
+		fpsOutputCounter = 0
 		fpsLastPrint = now
+		return int(fpsLast.Load()), true
+	}
+	return int(fpsLast.Load()), false
+}
+
+func printFPS() {
+	if fps, ok := tickFPS(); ok {
+		if *outputFPS > 0 {
+			slog.Info("FPS", "fps", fps, "output_fps", fpsOutputLast)
+			return
+		}
+		slog.Info("FPS", "fps", fps)
+	}
+}
+
+// flushFPS reports the frames counted since the last report, scaled to the
+// time that actually passed and rounded to a tenth, so runs ending within a
+// second still get a frame rate. It is called once the loop ends and reports nothing when no
+// frames are left to count.
+func flushFPS() (fps float64, ok bool) {
+	if !fpsInitialized || fpsCounter == 0 {
+		return 0, false
+	}
+	now := fpsClock()
+	elapsed := now.Sub(fpsLastPrint).Seconds()
+	if elapsed <= 0 {
+		return 0, false
+	}
+	fps = math.Round(float64(fpsCounter)/elapsed*10) / 10
+	outputRate := math.Round(float64(fpsOutputCounter)/elapsed*10) / 10
+	fpsCounter = 0
+	fpsOutputCounter = 0
+	fpsLastPrint = now
+
+	if *outputFPS > 0 {
+		slog.Info("FPS", "fps", fps, "output_fps", outputRate, "seconds", elapsed)
+	} else {
+		slog.Info("FPS", "fps", fps, "seconds", elapsed)
 	}
+	return fps, true
 }
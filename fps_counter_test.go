@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeFPSClock resets the FPS counter onto a clock that only moves when the
+// returned function advances it
+func fakeFPSClock(t *testing.T) (advance func(time.Duration)) {
+	now := time.Unix(1000, 0)
+	reset := func() {
+		fpsInitialized = false
+		fpsCounter, fpsOutputCounter, fpsOutputLast = 0, 0, 0
+		fpsLast.Store(0)
+	}
+	reset()
+	fpsClock = func() time.Time { return now }
+	t.Cleanup(func() {
+		fpsClock = time.Now
+		reset()
+	})
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestFlushFPSShortRun(t *testing.T) {
+	advance := fakeFPSClock(t)
+	if _, ok := flushFPS(); ok {
+		t.Fatal("reported a frame rate before any frame")
+	}
+
+	// 50 frames 5ms apart, a quarter of a second in all
+	for range 50 {
+		if _, ok := tickFPS(); ok {
+			t.Fatal("reported before a second passed")
+		}
+		advance(5 * time.Millisecond)
+	}
+	if fps, ok := flushFPS(); !ok || fps != 200 {
+		t.Fatalf("flushed %v, %v, want 200 frames per second", fps, ok)
+	}
+	if _, ok := flushFPS(); ok {
+		t.Fatal("reported the same frames twice")
+	}
+}
+
+func TestFlushFPSAfterFullSecond(t *testing.T) {
+	advance := fakeFPSClock(t)
+
+	// A second at 100 fps is reported by tickFPS, counting the frame it
+	// started timing on, the 30 frames after it are left for flushFPS
+	reported := 0
+	for range 131 {
+		advance(10 * time.Millisecond)
+		if fps, ok := tickFPS(); ok {
+			if reported != 0 {
+				t.Fatal("reported twice within a second")
+			}
+			reported = fps
+		}
+	}
+	if reported != 101 {
+		t.Fatalf("tickFPS reported %d, want 101", reported)
+	}
+	advance(200 * time.Millisecond)
+	if fps, ok := flushFPS(); !ok || fps != 60 {
+		t.Fatalf("flushed %v, %v, want the 30 frames of the last half second as 60", fps, ok)
+	}
+}
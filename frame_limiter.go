@@ -0,0 +1,35 @@
+package main
+
+import "time"
+
+// FrameLimiter paces a loop to a fixed number of frames per second. Frames
+// are scheduled from a fixed start, so short sleeps and slow frames do not
+// add up to drift.
+type FrameLimiter struct {
+	interval time.Duration
+	next     time.Time
+}
+
+func NewFrameLimiter(fps float64) *FrameLimiter {
+	return &FrameLimiter{interval: time.Duration(float64(time.Second) / fps)}
+}
+
+// Wait returns once the next frame is due, calling sleep for the time left
+// until it does. sleep may return early.
+func (l *FrameLimiter) Wait(sleep func(time.Duration)) {
+	now := time.Now()
+	if l.next.IsZero() {
+		l.next = now
+	}
+	for now.Before(l.next) {
+		sleep(l.next.Sub(now))
+		now = time.Now()
+	}
+
+	// After falling more than a frame behind start over from now instead of
+	// rushing out the missed frames
+	l.next = l.next.Add(l.interval)
+	if now.Sub(l.next) > l.interval {
+		l.next = now.Add(l.interval)
+	}
+}
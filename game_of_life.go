@@ -1,11 +1,24 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"io"
+	"log/slog"
 	"math/rand"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/veandco/go-sdl2/sdl"
 )
@@ -17,87 +30,405 @@ const (
 	DenseCells
 	SparsePixels
 	DensePixels
+	SparsePixelsHeader // SparsePixels with a generation header per frame
+	DeltaCells         // only the cells changed since the previous frame
+	DensePixels16      // DensePixels with a uint16 per channel
+	DeltaBitmap        // a bit per cell marking changes, then the changed states
 )
 
+// Names accepted by -protocol
+var protocolNames = map[string]Protocol{
+	"off":             Off,
+	"dense-cells":     DenseCells,
+	"sparse-pixels":   SparsePixels,
+	"dense-pixels":    DensePixels,
+	"sparse-header":   SparsePixelsHeader,
+	"delta-cells":     DeltaCells,
+	"dense-pixels-16": DensePixels16,
+	"delta-bitmap":    DeltaBitmap,
+}
+
+func (p Protocol) String() string {
+	for name, value := range protocolNames {
+		if value == p {
+			return name
+		}
+	}
+	return fmt.Sprintf("Protocol(%d)", int(p))
+}
+
+func (p Protocol) known() bool {
+	for _, value := range protocolNames {
+		if value == p {
+			return true
+		}
+	}
+	return false
+}
+
+// packsCells reports whether the protocol writes cells with packCell
+func (p Protocol) packsCells() bool {
+	return p == SparsePixels || p == SparsePixelsHeader || p == DeltaCells
+}
+
+func parseProtocol(name string) (Protocol, error) {
+	p, ok := protocolNames[name]
+	if !ok {
+		return Off, fmt.Errorf("unknown protocol %q", name)
+	}
+	return p, nil
+}
+
+// Protocol written to stdout, set from -protocol
+var protocol Protocol = PROTOCOL
+
 const (
 	PROTOCOL   = Off
 	VISUAL_OUT = true
-	EMPTY      = 0
-	BLUE       = 1
-	ORANGE     = 2
-	DEAD       = 3
 	gridWidth  = 1000
 	gridHeight = 1000
+
+	// First byte of every SparsePixelsHeader frame
+	SPARSE_MAGIC = 0x47
+	// Ends every frame of the packed cell protocols
+	END_OF_FRAME = 0xFFFFFFFF
+
+	// Smallest dimension where the 8 wrapped neighbors are distinct cells
+	MIN_GRID_SIZE = 3
 )
 
 type Game struct {
+	width    int
+	height   int
 	grid     [][]uint8
 	nextGrid [][]uint8
+	// Number of the generation in grid
+	generation int
+
+	// Goroutines used by Update, 0 means one per CPU
+	workers int
+	// How Update shares the columns between them
+	schedule Schedule
+	// The only cells Update computes, the whole grid when empty
+	active image.Rectangle
+	// Update writes into grid itself and nextGrid is nil, see updateColumnsInPlace
+	inPlace bool
+	// What lies past the edges of the grid
+	edge EdgeMode
+	// Which cells count as neighbors, the rule thresholds stay the same
+	neighborhood Neighborhood
+	rule         Rule
+	// A live cell dies when the other color has this many more neighbors
+	// than its own, 0 to treat both colors alike
+	enemyMargin int
+	// Live cells at least this old are drawn in a lighter elder shade, 0 for never
+	elderAge int
+	// A born cell is BLUE when its blue neighbors outnumber the orange ones
+	// by more than this, ORANGE otherwise
+	birthBias float64
+	// State each pinned cell is held at, EMPTY where not pinned, nil if none are
+	pinned [][]uint8
+	// Two states only, without colors or decay, see SetClassic
+	classic bool
+
+	// Previous generations, nil unless EnableHistory was called
+	history *History
+
+	// Generations each live cell has survived, nil unless EnableAge was called
+	age     [][]uint16
+	nextAge [][]uint16
+
+	// A uint8 per cell for callers, nil unless EnableAux was called
+	aux     [][]uint8
+	nextAux [][]uint8
+	auxFunc AuxFunc
+
+	// Last frame written by DeltaCells or DeltaBitmap, nil before the first one
+	deltaPrev [][]uint8
+
+	// Streaming texture for RenderTexture, created on first use
+	texture *sdl.Texture
+	// Point groups reused by Draw, one per color
+	points [][]sdl.Point
+	// Reused by DrawShapes the same way
+	rects [][]sdl.Rect
+	// Top left cell shown in the window, moved with the arrow keys
+	viewX, viewY int
+	// Toggled with M
+	minimapHidden bool
+	// Mouse painting, sized with [ and ]
+	brush Brush
+	// The board drawn 3x3 times, toggled with T
+	tilePreview bool
+	// Frames blended between generations with -smooth, and how far the one
+	// being drawn is from the previous generation to the current, 0 when
+	// drawing a whole generation
+	smoothFrames int
+	blend        float64
+	// Reused by DrawBlend, one group per pair of previous and current state
+	blendRects [][]sdl.Rect
+	// Fading marks where cells were live with -persistence, nil without
+	trails *Trails
+	// Cells with 0 to 8 live neighbors in the generation Update last read,
+	// nil without -neighbor-hist
+	neighborHist       *[9]int
+	neighborHistHidden bool
+
+	// Time Run shows the starting board for before the first generation
+	startDelay time.Duration
+	// Fraction of the start delay left while it runs, 0 otherwise
+	countdown float64
+
+	// Number of cells in each state, counted by Update for nextGrid
+	population [MAX_STATE + 1]int
+	// Number of cells Update gave a different state
+	changed int
+	paused  bool
+	// Set when losing focus paused the game, so only regaining it resumes
+	blurPaused bool
+	// Set to advance a paused game by one generation
+	step bool
+	// Signals Run reacts to between generations, see handleSignal
+	control chan os.Signal
+	// Set when the window asks to close
+	quit bool
+	// Set when the window or its renderer changed size or lost its textures,
+	// visualize recreates them before drawing the next frame
+	resized bool
+
+	// Paces Run when set
+	limiter *FrameLimiter
+	// Times Update when set
+	latency *LatencyTracker
+	// Sleeps after Update to cap the average CPU use when set
+	throttle *CPUThrottle
+	// Decouples the rate of the output stream from the simulation when set
+	outputPacer *OutputPacer
+	// Writes the output stream on its own goroutine when set
+	outputQueue *FrameQueue
+
+	// Called by Run after every generation
+	callbacks []GenerationFunc
+	// Changes the window makes to the board under RunDecoupled, applied by
+	// the simulating goroutine that owns it. Nil otherwise, see edit.
+	edits chan func(g *Game)
+}
+
+// NewGame creates a new Game of Life with a random initial state drawn from r
+func NewGame(width, height int, r *rand.Rand) (*Game, error) {
+	g, err := newEmptyGame(width, height, DoubleBuffer)
+	if err != nil {
+		return nil, err
+	}
+	g.Randomize(r)
+	return g, nil
+}
+
+// newEmptyGame allocates a game with every cell EMPTY, and the next grid
+// too unless buffers is ReuseBuffer
+func newEmptyGame(width, height int, buffers GridBuffers) (*Game, error) {
+	if width < MIN_GRID_SIZE || height < MIN_GRID_SIZE {
+		return nil, fmt.Errorf("grid size %dx%d is too small, both dimensions must be at least %d",
+			width, height, MIN_GRID_SIZE)
+	}
+	return allocGame(width, height, buffers), nil
 }
 
-// NewGame creates a new Game of Life with a random initial state
-func NewGame() *Game {
-	grid := make([][]uint8, gridWidth)
-	nextGrid := make([][]uint8, gridWidth)
+// makeGame allocates a double buffered game of any size, only boards that
+// are never updated (like patterns) may be smaller than MIN_GRID_SIZE
+func makeGame(width, height int) *Game {
+	return allocGame(width, height, DoubleBuffer)
+}
+
+func allocGame(width, height int, buffers GridBuffers) *Game {
+	grid := make([][]uint8, width)
 	for i := range grid {
-		grid[i] = make([]uint8, gridHeight)
-		nextGrid[i] = make([]uint8, gridHeight)
-		for j := range grid[i] {
-			grid[i][j] = uint8(rand.Int()) % 4
+		grid[i] = make([]uint8, height)
+	}
+	g := &Game{
+		width:   width,
+		height:  height,
+		grid:    grid,
+		edge:    Toroidal,
+		rule:    defaultRule,
+		inPlace: buffers == ReuseBuffer,
+	}
+	if !g.inPlace {
+		g.nextGrid = make([][]uint8, width)
+		for i := range g.nextGrid {
+			g.nextGrid[i] = make([]uint8, height)
 		}
 	}
-	return &Game{
-		grid:     grid,
-		nextGrid: nextGrid,
+	g.population[EMPTY] = width * height
+	return g
+}
+
+// Randomize fills the grid with random EMPTY, BLUE, ORANGE and DEAD cells,
+// seeded from r
+func (g *Game) Randomize(r *rand.Rand) {
+	fillNoise(g, r.Int63())
+	g.resetState()
+}
+
+// Reset makes every cell EMPTY and starts counting generations over
+func (g *Game) Reset() {
+	for x := range g.width {
+		clear(g.grid[x])
 	}
+	g.resetState()
 }
 
+// resetState starts a new run on whatever grid holds, pinned cells stay
+func (g *Game) resetState() {
+	g.generation = 0
+	for x := range g.width {
+		if g.age != nil {
+			clear(g.age[x])
+		}
+		if g.aux != nil {
+			clear(g.aux[x])
+		}
+		if g.pinned == nil {
+			continue
+		}
+		for y := range g.height {
+			if g.pinned[x][y] != EMPTY {
+				g.grid[x][y] = g.pinned[x][y]
+			}
+		}
+	}
+	g.countPopulation()
+}
+
+// countPopulation recounts the states of grid, for when it was not produced by Update
+func (g *Game) countPopulation() {
+	g.population = [MAX_STATE + 1]int{}
+	for x := range g.width {
+		for y := range g.height {
+			g.population[g.grid[x][y]]++
+		}
+	}
+}
+
+// Swap makes the generation Update worked out the current one. The one it
+// replaces goes to the history, in place Update has already pushed it.
 func (g *Game) Swap() {
-	// Swap current and next generation
-	g.grid, g.nextGrid = g.nextGrid, g.grid
+	if g.history != nil && !g.inPlace {
+		g.history.Push(g.grid)
+	}
+
+	// Swap current and next generation, in place grid already holds it
+	if !g.inPlace {
+		g.grid, g.nextGrid = g.nextGrid, g.grid
+	}
+	g.age, g.nextAge = g.nextAge, g.age
+	g.aux, g.nextAux = g.nextAux, g.aux
+	g.generation++
 }
 
-// CountNeighbors counts the number of live neighbors for a cell
-func (g *Game) CountNeighbors(x, y int) (blue_count, orange_count int) {
-	for i := -1; i <= 1; i++ {
-		for j := -1; j <= 1; j++ {
-			if i == 0 && j == 0 {
-				continue // Skip the cell itself
-			}
+// SwapAndCount swaps like Swap and returns how many cells differ between the
+// old grid and the new one. The count is taken by Update while it fills the
+// next grid, so no second pass over the cells is needed.
+func (g *Game) SwapAndCount() int {
+	g.Swap()
+	return g.changed
+}
 
-			// Wrap around the edges
-			nx := (x + i + gridWidth) % gridWidth
-			ny := (y + j + gridHeight) % gridHeight
-			switch g.grid[nx][ny] {
-			case BLUE:
-				blue_count++
-			case ORANGE:
-				orange_count++
-			}
+// Generation returns how many times the game was advanced since it was created or reset
+func (g *Game) Generation() int {
+	return g.generation
+}
+
+// Equal reports whether both games have the same size and every cell in the
+// same state. The generation and settings are not compared.
+func (g *Game) Equal(other *Game) bool {
+	return g.width == other.width && g.height == other.height && EqualGrid(g.grid, other.grid)
+}
+
+// EqualGrid reports whether two grids have the same shape and cells,
+// stopping at the first difference
+func EqualGrid(a, b [][]uint8) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for x := range a {
+		if !bytes.Equal(a[x], b[x]) {
+			return false
 		}
 	}
+	return true
+}
+
+// CountNeighbors counts the number of live neighbors for a cell
+func (g *Game) CountNeighbors(x, y int) (blue_count, orange_count int) {
+	g.forEachNeighbor(x, y, func(nx, ny int) {
+		switch g.grid[nx][ny] {
+		case BLUE:
+			blue_count++
+		case ORANGE:
+			orange_count++
+		}
+	})
 	return
 }
 
+// CellChange returns the state a cell has in the next generation. It runs
+// two steps in a fixed order: the neighbor rule first, then the decay timer.
+// Each step only looks at the state the cell started the generation in, so
+// a cell the rule kills enters the decay at DEAD and first advances one
+// generation later, and the rule can never reset or skip a decay stage.
 func (g *Game) CellChange(x, y int) uint8 {
+	return g.cellChange(x, y, nil)
+}
+
+// cellChange is CellChange adding the live neighbor count of the cell to
+// hist unless it is nil. The rule's own count is reused, only the cells the
+// rule does not count are counted for hist alone.
+func (g *Game) cellChange(x, y int, hist *[9]int) uint8 {
+	if g.pinned != nil && g.pinned[x][y] != EMPTY {
+		if hist != nil {
+			g.tallyNeighbors(x, y, hist)
+		}
+		return g.pinned[x][y]
+	}
+	if g.classic {
+		return g.classicChange(x, y, hist)
+	}
+
 	cell := g.grid[x][y]
+	return advanceDecay(cell, g.neighborRule(x, y, cell, hist))
+}
+
+// tallyNeighbors is CountNeighbors adding the total to hist unless it is nil
+func (g *Game) tallyNeighbors(x, y int, hist *[9]int) (blue_count, orange_count int) {
+	blue_count, orange_count = g.CountNeighbors(x, y)
+	if hist != nil {
+		hist[blue_count+orange_count]++
+	}
+	return
+}
+
+// neighborRule is the first step of CellChange: births, survivals and
+// deaths decided by the neighbors. Decaying cells are left as they are
+// without counting their neighbors, unless hist needs them.
+func (g *Game) neighborRule(x, y int, cell uint8, hist *[9]int) uint8 {
 	if cell >= DEAD {
-		if cell == 6 {
-			return EMPTY
+		if hist != nil {
+			g.tallyNeighbors(x, y, hist)
 		}
-		return cell + 1
+		return cell
 	}
 
-	blue_count, orange_count := g.CountNeighbors(x, y)
+	blue_count, orange_count := g.tallyNeighbors(x, y, hist)
 	count := blue_count + orange_count
 
 	if (cell == BLUE) || (cell == ORANGE) {
-		if (3 <= count) && (count <= 5) {
+		if g.rule.Survival[count] && !g.outnumbered(cell, blue_count, orange_count) {
 			return cell
 		}
 		return DEAD
-	} else if (cell == EMPTY) && (count == 3) {
-		if blue_count > orange_count {
+	} else if (cell == EMPTY) && g.rule.Birth[count] {
+		if float64(blue_count) > float64(orange_count)+g.birthBias {
 			return BLUE
 		}
 		return ORANGE
@@ -105,163 +436,359 @@ func (g *Game) CellChange(x, y int) uint8 {
 	return cell
 }
 
-// Update advances the game to the next generation
+// advanceDecay is the second step of CellChange. A cell that started the
+// generation decaying moves one stage on, past the last one to EMPTY,
+// whatever the rule picked. Any other cell keeps next.
+func advanceDecay(cell, next uint8) uint8 {
+	switch {
+	case cell < DEAD:
+		return next
+	case cell == MAX_STATE:
+		return EMPTY
+	}
+	return cell + 1
+}
+
+// outnumbered reports whether a live cell is surrounded by too many of the other color
+func (g *Game) outnumbered(cell uint8, blue_count, orange_count int) bool {
+	if g.enemyMargin <= 0 {
+		return false
+	}
+	if cell == BLUE {
+		return orange_count-blue_count >= g.enemyMargin
+	}
+	return blue_count-orange_count >= g.enemyMargin
+}
+
+// Update advances the game to the next generation. Only the active
+// rectangle is computed when one is set, the cells outside it stay as they are.
 func (g *Game) Update() {
+	// In place the current generation is about to be overwritten
+	if g.history != nil && g.inPlace {
+		g.history.Push(g.grid)
+	}
+	area := g.activeArea()
+	partial := !g.active.Empty()
+	if partial {
+		g.copyFrozen()
+	}
 
-	numCPU := runtime.NumCPU()
+	numCPU := g.workers
+	if numCPU <= 0 {
+		numCPU = runtime.NumCPU()
+	}
+	numCPU = min(numCPU, area.Dx())
 	var wg sync.WaitGroup
 
+	// Each worker tallies its own rows, merged after the wait. Under an
+	// active rectangle the states it replaced are tallied too, the cells
+	// outside keep their share of the population.
+	counts := make([][MAX_STATE + 1]int, numCPU)
+	replaced := make([][MAX_STATE + 1]int, numCPU)
+	changes := make([]int, numCPU)
+	// Live neighbor counts for -neighbor-hist, not counted while it is hidden
+	var hists [][9]int
+	if g.neighborHist != nil && !g.neighborHistHidden {
+		hists = make([][9]int, numCPU)
+	}
+	// Edge columns of every range, written in place once all are done
+	var held [][]heldColumn
+	if g.inPlace {
+		held = make([][]heldColumn, numCPU)
+	}
+
 	// Divide work based on CPU cores
-	rowsPerWorker := gridHeight / numCPU
+	var queue *TileQueue
+	if g.schedule == Tiles {
+		queue = NewTileQueue(area.Min.X, area.Max.X, numCPU)
+	}
+	rowsPerWorker := area.Dx() / numCPU
 	for i := range numCPU {
 
-		startRow := i * rowsPerWorker
+		startRow := area.Min.X + i*rowsPerWorker
 		endRow := startRow + rowsPerWorker
 		if i == numCPU-1 {
-			endRow = gridWidth // Handle remainder
+			endRow = area.Max.X // Handle remainder
 		}
 
+		var old *[MAX_STATE + 1]int
+		if partial {
+			old = &replaced[i]
+		}
+		var hist *[9]int
+		if hists != nil {
+			hist = &hists[i]
+		}
+		var keep *[]heldColumn
+		if held != nil {
+			keep = &held[i]
+		}
 		wg.Add(1)
-		go func(startRow, endRow int) {
+		go func(startRow, endRow int, count, old *[MAX_STATE + 1]int, hist *[9]int, changed *int, keep *[]heldColumn) {
 			defer wg.Done()
-			for x := startRow; x < endRow; x++ {
-				for y := range gridHeight {
-					g.nextGrid[x][y] = g.CellChange(x, y)
+			update := func(x0, x1 int) {
+				if keep != nil {
+					g.updateColumnsInPlace(x0, x1, area.Min.Y, area.Max.Y, count, old, hist, changed, keep)
+					return
+				}
+				g.updateColumns(x0, x1, area.Min.Y, area.Max.Y, count, old, hist, changed)
+			}
+			if queue == nil {
+				update(startRow, endRow)
+				return
+			}
+			for {
+				start, end, ok := queue.Take()
+				if !ok {
+					return
 				}
+				update(start, end)
 			}
-		}(startRow, endRow)
+		}(startRow, endRow, &counts[i], old, hist, &changes[i], keep)
 	}
 
 	wg.Wait()
+	for _, columns := range held {
+		for _, column := range columns {
+			copy(g.grid[column.x][area.Min.Y:], column.cells)
+		}
+	}
+
+	if !partial {
+		g.population = [MAX_STATE + 1]int{}
+	}
+	for i, count := range counts {
+		for state, n := range count {
+			g.population[state] += n - replaced[i][state]
+		}
+	}
+	g.changed = 0
+	for _, n := range changes {
+		g.changed += n
+	}
+	if hists != nil {
+		*g.neighborHist = [9]int{}
+		for _, hist := range hists {
+			for n, cells := range hist {
+				g.neighborHist[n] += cells
+			}
+		}
+	}
+}
+
+// updateColumns writes the next generation of the cells from x0 to x1-1 and
+// y0 to y1-1 into nextGrid, adding the new states to count, the states they
+// replace to old and their live neighbor counts to hist unless those are nil
+// and the changed cells to changed
+func (g *Game) updateColumns(x0, x1, y0, y1 int, count, old *[MAX_STATE + 1]int, hist *[9]int, changed *int) {
+	for x := x0; x < x1; x++ {
+		for y := y0; y < y1; y++ {
+			cell := g.cellChange(x, y, hist)
+			g.nextGrid[x][y] = cell
+			count[cell]++
+			if old != nil {
+				old[g.grid[x][y]]++
+			}
+			if cell != g.grid[x][y] {
+				*changed++
+			}
+			if g.age != nil {
+				g.nextAge[x][y] = g.nextCellAge(x, y, cell)
+			}
+			if g.aux != nil {
+				g.nextAux[x][y] = g.nextCellAux(x, y, cell)
+			}
+		}
+	}
+}
+
+// Live returns the number of BLUE and ORANGE cells after the last Update.
+// Decaying cells are not counted, they turn EMPTY on their own.
+func (g *Game) Live() int {
+	return g.population[BLUE] + g.population[ORANGE]
 }
 
 // Draw renders the current state of the game to an SDL texture
 func (g *Game) Draw(renderer *sdl.Renderer) {
+	colors := g.colors(&windowPalette)
+
 	// Prepare point groups per color
-	var bluePoints []sdl.Point
-	var orangePoints []sdl.Point
-	var blackPoints []sdl.Point
-	var darkPoints []sdl.Point
-	var greyPoints []sdl.Point
-
-	bluePoints = make([]sdl.Point, 0, gridWidth*gridHeight/8)
-	orangePoints = make([]sdl.Point, 0, gridWidth*gridHeight/8)
-	blackPoints = make([]sdl.Point, 0, gridWidth*gridHeight/8)
-	darkPoints = make([]sdl.Point, 0, gridWidth*gridHeight/8)
-	greyPoints = make([]sdl.Point, 0, gridWidth*gridHeight/8)
+	if len(g.points) != len(colors) {
+		g.points = make([][]sdl.Point, len(colors))
+	}
+	for i := range g.points {
+		g.points[i] = g.points[i][:0]
+	}
 
 	// Collect points by color
-	for x := range gridWidth {
-		for y := range gridHeight {
-			switch g.grid[x][y] {
-			case ORANGE:
-				orangePoints = append(orangePoints, sdl.Point{X: int32(x), Y: int32(y)})
-			case BLUE:
-				bluePoints = append(bluePoints, sdl.Point{X: int32(x), Y: int32(y)})
-			case DEAD:
-				blackPoints = append(blackPoints, sdl.Point{X: int32(x), Y: int32(y)})
-			case DEAD + 1:
-				darkPoints = append(darkPoints, sdl.Point{X: int32(x), Y: int32(y)})
-			case DEAD + 2:
-				greyPoints = append(greyPoints, sdl.Point{X: int32(x), Y: int32(y)})
-			}
+	for x := range g.width {
+		for y := range g.height {
+			i := g.colorIndex(x, y)
+			g.points[i] = append(g.points[i], sdl.Point{X: int32(x), Y: int32(y)})
 		}
 	}
 
-	// Draw each color group in batches
-	if len(bluePoints) > 0 {
-		renderer.SetDrawColor(0x0, 0x99, 0xFF, 0xFF)
-		renderer.DrawPoints(bluePoints)
-	}
-	if len(orangePoints) > 0 {
-		renderer.SetDrawColor(0xFF, 0x99, 0x00, 0xFF)
-		renderer.DrawPoints(orangePoints)
-	}
-	if len(blackPoints) > 0 {
-		renderer.SetDrawColor(0x66, 0x66, 0x66, 0xFF)
-		renderer.DrawPoints(blackPoints)
-	}
-	if len(darkPoints) > 0 {
-		renderer.SetDrawColor(0x7f, 0x7f, 0x7f, 0xFF)
-		renderer.DrawPoints(darkPoints)
-	}
-	if len(greyPoints) > 0 {
-		renderer.SetDrawColor(0x99, 0x99, 0x99, 0xFF)
-		renderer.DrawPoints(greyPoints)
+	// Draw each color group in batches, the background is already cleared
+	for i, points := range g.points {
+		if len(points) == 0 || colors[i] == colors[EMPTY] {
+			continue
+		}
+		setDrawColor(renderer, colors[i])
+		inBatches(points, renderer.DrawPoints)
 	}
 }
 
-func (g *Game) outputSparsePixels() error {
-	rowData := make([]byte, 4*gridWidth)
-	for y := range gridHeight {
-		rowData = rowData[:0]
-		for x := range gridWidth {
-			state := g.grid[x][y]
-			if state == EMPTY {
-				continue
+// ForEachLive calls fn for every cell that is not EMPTY, decaying ones
+// included, in scan order: row by row from the top, left to right. Nothing
+// is allocated, so it suits exporters that would otherwise collect cells.
+func (g *Game) ForEachLive(fn func(x, y int, state uint8)) {
+	for y := range g.height {
+		for x := range g.width {
+			if state := g.grid[x][y]; state != EMPTY {
+				fn(x, y, state)
 			}
-
-			// Pack x (12 bits), y (12 bits), state (8 bits)
-			packed := uint32(x&0xFFF) | uint32((y&0xFFF)<<12) | (uint32(state) << 24)
-
-			var cell [4]byte
-			binary.LittleEndian.PutUint32(cell[:], packed)
-
-			rowData = append(rowData, cell[:]...)
 		}
+	}
+}
 
-		_, err := os.Stdout.Write(rowData)
+// outputSparsePixels writes every non-empty cell followed by an end-of-frame marker.
+// With header set the frame starts with SPARSE_MAGIC, the generation and the
+// number of cells that follow, each a little endian uint32.
+func (g *Game) outputSparsePixels(w io.Writer, header bool) error {
+	if header {
+		var head [9]byte
+		head[0] = SPARSE_MAGIC
+		binary.LittleEndian.PutUint32(head[1:], uint32(g.generation))
+		binary.LittleEndian.PutUint32(head[5:], uint32(g.width*g.height-g.population[EMPTY]))
+		_, err := w.Write(head[:])
 		if err != nil {
 			return err
 		}
 	}
 
+	// Written a row's worth of cells at a time, however the cells fall
+	var err error
+	cells := make([]byte, 0, 4*g.width)
+	g.ForEachLive(func(x, y int, state uint8) {
+		if err != nil {
+			return
+		}
+		cells = binary.LittleEndian.AppendUint32(cells, packCell(x, y, state))
+		if len(cells) == cap(cells) {
+			_, err = w.Write(cells)
+			cells = cells[:0]
+		}
+	})
+	if err == nil && len(cells) > 0 {
+		_, err = w.Write(cells)
+	}
+	if err != nil {
+		return err
+	}
+
 	// End-of-frame marker
 	var eof [4]byte
-	binary.LittleEndian.PutUint32(eof[:], 0xFFFFFFFF)
-	_, err := os.Stdout.Write(eof[:])
+	binary.LittleEndian.PutUint32(eof[:], END_OF_FRAME)
+	_, err = w.Write(eof[:])
 	return err
 }
 
-func rgba(r, g, b uint8) uint32 {
-	return uint32(b) | uint32(g)<<8 | uint32(r)<<16
-}
-func (g *Game) ouputDensePixels() error {
-	// Create a buffer for a single row
-	row := make([]byte, gridWidth*4)
-
-	for y := range gridHeight {
-		for x := range gridWidth {
-			var color uint32
-			switch g.grid[x][y] {
-			case BLUE:
-				color = rgba(0, 0, 255)
-			case ORANGE:
-				color = rgba(255, 128, 0)
-			case DEAD:
-				color = rgba(0, 0, 0)
-			case DEAD + 1:
-				color = rgba(136, 136, 136)
-			case DEAD + 2:
-				color = rgba(160, 160, 160)
-			case DEAD + 3:
-				color = rgba(238, 238, 238)
-			default:
-				color = rgba(255, 255, 255)
-			}
-			binary.LittleEndian.PutUint32(row[x*4:], color)
+// Largest coordinate and state packCell holds. State 0xFF is left out so no
+// packed cell can be END_OF_FRAME.
+const (
+	PACK_MAX_COORD = 0xFFF
+	PACK_MAX_STATE = 0xFE
+)
+
+// Fails to compile once MAX_STATE no longer fits the packed protocols
+var _ [PACK_MAX_STATE - MAX_STATE]struct{}
+
+// checkPacked reports an error when cells of a width by height grid with
+// states up to maxState would not survive packCell. It masks instead of
+// checking, so a bigger grid would silently wrap around.
+func checkPacked(width, height, maxState int) error {
+	if width-1 > PACK_MAX_COORD || height-1 > PACK_MAX_COORD {
+		return fmt.Errorf("the sparse and delta-cells protocols cannot pack a %dx%d grid, at most %dx%d fits",
+			width, height, PACK_MAX_COORD+1, PACK_MAX_COORD+1)
+	}
+	if maxState < 0 || maxState > PACK_MAX_STATE {
+		return fmt.Errorf("the sparse and delta-cells protocols cannot pack state %d, at most %d fits", maxState, PACK_MAX_STATE)
+	}
+	return nil
+}
+
+// packCell packs x (12 bits), y (12 bits), state (8 bits)
+func packCell(x, y int, state uint8) uint32 {
+	return uint32(x&0xFFF) | uint32((y&0xFFF)<<12) | (uint32(state) << 24)
+}
+
+func unpackCell(packed uint32) (x, y int, state uint8) {
+	return int(packed & 0xFFF), int(packed >> 12 & 0xFFF), uint8(packed >> 24)
+}
+
+// ouputDensePixels writes 4 bytes per pixel, or with wide 4 little endian
+// uint16, each byte b of the color becoming b*257 so 0xFF maps to 0xFFFF.
+func (g *Game) ouputDensePixels(w io.Writer, wide bool) error {
+	colors := g.colors(&pixelPalette)
+	m := *margin
+	width, height := g.width+2*m, g.height+2*m
+	if *downsample > 1 {
+		width, height = g.downsampledSize(*downsample, m)
+	}
+
+	// Batch as many whole rows as fit in the chunk, at least one
+	rowSize := width * 4
+	if wide {
+		rowSize *= 2
+	}
+	rowsPerChunk := max(1, *chunkSize/rowSize)
+	chunk := make([]byte, 0, rowsPerChunk*rowSize)
+	var narrow []byte
+	if wide {
+		narrow = make([]byte, width*4)
+	}
+	var counts []int
+	if *downsample > 1 {
+		counts = make([]int, (MAX_STATE+1)*len(colors))
+	}
+
+	for y := range height {
+		row := chunk[len(chunk) : len(chunk)+rowSize]
+		if wide {
+			row = narrow
 		}
-		_, err := os.Stdout.Write(row)
-		if err != nil {
-			return err
+		switch {
+		case *downsample > 1:
+			g.fillDownsampledRow(row, y, *downsample, m, colors, counts)
+		case m > 0:
+			g.fillMarginRow(row, y, m, colors)
+		default:
+			g.fillPixelRow(row, y, colors)
+		}
+		if wide {
+			widenRow(chunk[len(chunk):len(chunk)+rowSize], narrow)
+		}
+		chunk = chunk[:len(chunk)+rowSize]
+
+		if len(chunk) == cap(chunk) || y == height-1 {
+			_, err := w.Write(chunk)
+			if err != nil {
+				return err
+			}
+			chunk = chunk[:0]
 		}
 	}
 	return nil
 }
 
-func (g *Game) ouputDenseCells() error {
-	for y := range gridHeight {
-		_, err := os.Stdout.Write(g.grid[y])
+func (g *Game) ouputDenseCells(w io.Writer) error {
+	// grid is column-major, so gather each output row first
+	row := make([]byte, g.width)
+	for y := range g.height {
+		for x := range g.width {
+			row[x] = g.grid[x][y]
+		}
+		_, err := w.Write(row)
 		if err != nil {
 			return err
 		}
@@ -269,24 +796,114 @@ func (g *Game) ouputDenseCells() error {
 	return nil
 }
 
+func (game *Game) handleEvent(event sdl.Event) {
+	switch e := event.(type) {
+	case *sdl.QuitEvent:
+		game.quit = true // Run returns before the next frame
+	case *sdl.KeyboardEvent:
+		if e.State != sdl.PRESSED {
+			break
+		}
+		switch e.Keysym.Sym {
+		case sdl.K_ESCAPE:
+			game.quit = true
+		case sdl.K_SPACE:
+			game.paused = !game.paused
+			game.blurPaused = false
+		case sdl.K_n:
+			game.requestStep()
+		case sdl.K_m:
+			game.minimapHidden = !game.minimapHidden
+		case sdl.K_h:
+			game.neighborHistHidden = !game.neighborHistHidden
+		case sdl.K_LEFTBRACKET:
+			game.brush.resize(-1)
+		case sdl.K_RIGHTBRACKET:
+			game.brush.resize(1)
+		case sdl.K_b:
+			game.brush.circle = !game.brush.circle
+		case sdl.K_t:
+			game.tilePreview = !game.tilePreview
+			game.brush.hovered = false
+		case sdl.K_LEFT:
+			game.pan(-1, 0)
+		case sdl.K_RIGHT:
+			game.pan(1, 0)
+		case sdl.K_UP:
+			game.pan(0, -1)
+		case sdl.K_DOWN:
+			game.pan(0, 1)
+		case sdl.K_c:
+			// The simulating goroutine owns the grid under -decouple
+			if *decouple {
+				slog.Warn("the engine cannot be switched with -decouple")
+				break
+			}
+			game.SetClassic(!game.classic)
+			slog.Info("engine", "mode", game.engineName(), "generation", game.Generation())
+		case sdl.K_p:
+			usePalettePreset((palettePreset + 1) % len(palettePresets))
+			slog.Info("palette", "preset", palettePresets[palettePreset].name)
+		}
+	case *sdl.WindowEvent:
+		switch e.Event {
+		case sdl.WINDOWEVENT_SIZE_CHANGED:
+			game.resized = true
+		case sdl.WINDOWEVENT_FOCUS_LOST:
+			if *pauseOnBlur && !game.paused {
+				game.paused = true
+				game.blurPaused = true
+			}
+		case sdl.WINDOWEVENT_FOCUS_GAINED:
+			if game.blurPaused {
+				game.paused = false
+				game.blurPaused = false
+			}
+		}
+	case *sdl.MouseButtonEvent, *sdl.MouseMotionEvent:
+		game.handleMouse(event)
+	case *sdl.RenderEvent:
+		// Some backends lose their textures along with the render targets
+		game.resized = true
+	}
+}
+
+// wait sleeps for up to delay, returning early when the window gets an event
+func (game *Game) wait(delay time.Duration) {
+	if !*visual {
+		time.Sleep(delay)
+		return
+	}
+	if event := sdl.WaitEventTimeout(int(delay.Milliseconds())); event != nil {
+		game.handleEvent(event)
+	}
+}
+
 func (game *Game) visualize(renderer *sdl.Renderer) {
 	// Poll for events to keep the window responsive
 	for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-		switch e := event.(type) {
-		case *sdl.QuitEvent:
-			os.Exit(0) // exit the program cleanly
-		case *sdl.KeyboardEvent:
-			if e.Keysym.Sym == sdl.K_ESCAPE && e.State == sdl.PRESSED {
-				os.Exit(0)
-			}
-		}
+		game.handleEvent(event)
+	}
+	if game.resized {
+		slog.Debug("recreating window textures")
+		game.releaseTexture()
+		renderer.SetViewport(nil)
+		game.resized = false
+	}
+
+	if game.trails != nil {
+		game.trails.Update(game, time.Now())
 	}
 
-	// Clear the screen with white
-	renderer.SetDrawColor(255, 255, 255, 255)
+	// Clear the screen with the EMPTY color
+	setDrawColor(renderer, windowPalette[EMPTY])
 	renderer.Clear()
 
-	game.Draw(renderer)
+	game.drawView(renderer)
+	game.drawBrush(renderer)
+	if game.countdown > 0 {
+		game.drawCountdown(renderer)
+	}
 
 	// Update the screen
 	renderer.Present()
@@ -294,65 +911,567 @@ func (game *Game) visualize(renderer *sdl.Renderer) {
 
 	printFPS()
 }
-func (g *Game) OutputAll(renderer *sdl.Renderer) {
-	if VISUAL_OUT {
+
+// OutputAll shows the current generation in the window and writes it to out
+// in the selected protocol
+func (g *Game) OutputAll(renderer *sdl.Renderer, out io.Writer) error {
+	if *visual {
 		g.visualize(renderer)
 	}
+	return g.writeOutput(out)
+}
 
-	switch PROTOCOL {
+// writeOutput writes the current generation to out, through the output
+// pacer and queue when they are set
+func (g *Game) writeOutput(out io.Writer) error {
+	frame := g.writeFrame
+	if g.outputPacer != nil {
+		frame = func(w io.Writer) error { return g.outputPacer.Write(w, g.writeFrame) }
+	}
+	if g.outputQueue != nil {
+		return g.outputQueue.Write(frame)
+	}
+	return frame(out)
+}
+
+// writeFrame writes the current generation to out in the selected protocol
+func (g *Game) writeFrame(out io.Writer) error {
+	// A -listen client may pick a packed protocol after the start
+	if protocol.packsCells() {
+		if err := checkPacked(g.width, g.height, MAX_STATE); err != nil {
+			return err
+		}
+	}
+	switch protocol {
 	case DensePixels:
-		g.ouputDensePixels()
+		return g.ouputDensePixels(out, false)
+	case DensePixels16:
+		return g.ouputDensePixels(out, true)
 	case DenseCells:
-		g.ouputDenseCells()
+		return g.ouputDenseCells(out)
 	case SparsePixels:
-		g.outputSparsePixels()
+		return g.outputSparsePixels(out, false)
+	case SparsePixelsHeader:
+		return g.outputSparsePixels(out, true)
+	case DeltaCells:
+		return g.outputDeltaCells(out)
+	case DeltaBitmap:
+		return g.outputDeltaBitmap(out)
 	}
+	return nil
+}
+
+// openWindow initializes SDL and creates the window with its renderer.
+// Nothing is left initialized when it fails.
+func openWindow(width, height int) (*sdl.Window, *sdl.Renderer, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, err
+	}
+
+	window, err := sdl.CreateWindow(
+		"Conway's Game of Life",
+		sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		int32(width), int32(height),
+		sdl.WINDOW_SHOWN|sdl.WINDOW_RESIZABLE,
+	)
+	if err != nil {
+		sdl.Quit()
+		return nil, nil, err
+	}
+
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED)
+	if err != nil {
+		window.Destroy()
+		sdl.Quit()
+		return nil, nil, err
+	}
+	return window, renderer, nil
+}
+
+// newStartGame creates the initial board: random cells, or an image or
+// pattern on an empty grid, or the board of a -resume snapshot
+func newStartGame() (*Game, error) {
+	if *resume != "" {
+		return resumeGame(*resume)
+	}
+
+	var pattern *Pattern
+	var err error
+	if strings.EqualFold(filepath.Ext(*patternPath), ".csv") {
+		pattern, err = loadCSVPattern(*patternPath)
+	} else if *patternPath != "" {
+		pattern, err = LoadRLE(*patternPath)
+	} else if *presetName != "" {
+		pattern, err = LoadPreset(*presetName)
+	} else if *soup != "" {
+		pattern, err = loadSoup(*soup)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var game *Game
+	if pattern == nil && *seedImage == "" {
+		if *seed == 0 {
+			*seed = time.Now().UnixNano()
+		}
+		if err := checkMemory(*width, *height); err != nil {
+			return nil, err
+		}
+		game, err = newSeededGame(*width, *height, *seed)
+		if err != nil {
+			return nil, err
+		}
+		game.rule, err = startRule("")
+		return game, err
+	}
+
+	w, h := *width, *height
+	if pattern != nil {
+		pattern, err = pattern.Orient(*orient)
+		if err != nil {
+			return nil, err
+		}
+		// Make room for the whole pattern unless the size was chosen
+		if !flagSet("width") {
+			w = max(w, pattern.Width)
+		}
+		if !flagSet("height") {
+			h = max(h, pattern.Height)
+		}
+	}
+	if err := checkMemory(w, h); err != nil {
+		return nil, err
+	}
+	game, err = newEmptyGame(w, h, gridBuffers())
+	if err != nil {
+		return nil, err
+	}
+
+	if *seedImage != "" {
+		img, err := loadImage(*seedImage)
+		if err != nil {
+			return nil, err
+		}
+		game.SeedImage(img, ImageThresholds{Dark: *seedDark, Light: *seedLight, Warm: *seedWarm})
+	}
+	if pattern == nil {
+		game.rule, err = startRule("")
+		return game, err
+	}
+
+	game.rule, err = startRule(pattern.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	// Centered unless placed
+	x := (game.width - pattern.Width) / 2
+	y := (game.height - pattern.Height) / 2
+	if *place != "" {
+		if _, err := fmt.Sscanf(*place, "%d,%d", &x, &y); err != nil {
+			return nil, fmt.Errorf("bad -place %q, expected x,y", *place)
+		}
+	}
+	game.Stamp(pattern.Game(), x, y, Overwrite)
+	return game, nil
+}
+
+// configureGame applies the flags that change how a game runs
+func configureGame(game *Game) error {
+	var err error
+	game.workers = *workers
+	game.schedule, err = parseSchedule(*scheduleFlag)
+	if err != nil {
+		return err
+	}
+	game.enemyMargin = *enemyMargin
+	game.birthBias = *birthBias
+	game.brush = newBrush()
+	game.tilePreview = *tilePreview
+	game.smoothFrames = *smooth
+	if *persistence > 0 && *visual {
+		game.trails = NewTrails(time.Duration(*persistence*float64(time.Millisecond)), game.width, game.height)
+	}
+	if *neighborHist && *visual {
+		game.neighborHist = new([9]int)
+	}
+	if *activeRect != "" {
+		game.active, err = parseActiveRect(*activeRect, game.width, game.height)
+		if err != nil {
+			return err
+		}
+	}
+	game.edge, err = parseEdgeMode(*edgeFlag)
+	if err != nil {
+		return err
+	}
+	if !*wrapX {
+		game.edge &^= WrapX
+	}
+	if !*wrapY {
+		game.edge &^= WrapY
+	}
+	game.neighborhood, err = parseNeighborhood(*neighborhoodFlag)
+	if err != nil {
+		return err
+	}
+	if *margin < 0 || (*margin > 0 && game.edge != Toroidal) {
+		return fmt.Errorf("-margin needs a positive size and wrapping on both axes")
+	}
+	if *pins != "" || *pinFile != "" {
+		cells, err := parsePins(*pins)
+		if err != nil {
+			return err
+		}
+		if *pinFile != "" {
+			fileCells, err := loadPins(*pinFile)
+			if err != nil {
+				return err
+			}
+			cells = append(cells, fileCells...)
+		}
+		if err := game.Pin(cells); err != nil {
+			return err
+		}
+	}
+	game.SetClassic(*classic)
+
+	if colorMode == ColorAge {
+		game.EnableAge()
+	}
+	if colorMode == ColorAux {
+		game.EnableAux(auxQuiet)
+	}
+	if *elderAge > 0 {
+		game.elderAge = *elderAge
+		game.EnableAge()
+	}
+	if *historyDepth > 0 {
+		game.EnableHistory(*historyDepth)
+	}
+	if colorMode == ColorDiff {
+		game.EnableHistory(1)
+	}
+	return nil
+}
+
+// configureOutput applies the flags that change what is drawn and streamed
+func configureOutput() error {
+	var err error
+	renderMode, err = parseRenderMode(*renderFlag)
+	if err != nil {
+		return err
+	}
+	if *zoom < 1 {
+		return fmt.Errorf("-zoom must be at least 1")
+	}
+	if err := checkBrushShape(*brushShape); err != nil {
+		return err
+	}
+	if *drawBatch < 0 {
+		return fmt.Errorf("-draw-batch cannot be negative")
+	}
+	if err := checkMinimapCorner(*minimapCorner); err != nil {
+		return err
+	}
+	colorMode, err = parseColorMode(*colorFlag)
+	if err != nil {
+		return err
+	}
+	if *activityColorFlag != "" {
+		if _, err := parseRGB(*activityColorFlag); err != nil {
+			return err
+		}
+	}
+	preset, err := findPalettePreset(*palettePresetFlag)
+	if err != nil {
+		return err
+	}
+	usePalettePreset(preset)
+	protocol, err = parseProtocol(*protocolFlag)
+	return err
 }
 
 func main() {
-	game := NewGame()
+	flag.Parse()
+	given := givenFlags(flag.CommandLine)
+	if err := applyEnv(flag.CommandLine, given); err != nil {
+		fmt.Fprintln(os.Stderr, "golife:", err)
+		os.Exit(1)
+	}
+	if *scene != "" {
+		if err := loadScene(*scene, given); err != nil {
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+	}
+	if err := setupLogging(*logLevel); err != nil {
+		fmt.Fprintln(os.Stderr, "golife: bad -log-level:", err)
+		os.Exit(1)
+	}
+
+	if err := configureOutput(); err != nil {
+		fmt.Fprintln(os.Stderr, "golife:", err)
+		os.Exit(1)
+	}
+	if err := checkRunFlags(); err != nil {
+		fmt.Fprintln(os.Stderr, "golife:", err)
+		os.Exit(1)
+	}
+
+	if *replayPath != "" {
+		if err := runReplay(os.Stdout, *replayPath, *seek); err != nil {
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *selfTest {
+		if err := runSelfTest(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *compare != "" {
+		if err := runCompare(*compare); err != nil {
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *search > 0 {
+		if err := runSearch(*search); err != nil {
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *boards > 0 {
+		if err := runBoards(*boards); err != nil {
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	game, err := newStartGame()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "golife:", err)
+		os.Exit(1)
+	}
+	if err := configureGame(game); err != nil {
+		fmt.Fprintln(os.Stderr, "golife:", err)
+		os.Exit(1)
+	}
+	if protocol.packsCells() {
+		if err := checkPacked(game.width, game.height, MAX_STATE); err != nil {
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+	}
+	if *dumpRule {
+		if err := game.WriteRuleTable(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *dryRun {
+		writeSummary(os.Stdout, game)
+		return
+	}
+	if *benchmarkRule > 0 {
+		if err := game.BenchmarkRule(os.Stdout, *benchmarkRule); err != nil {
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var renderer *sdl.Renderer = nil
-	if VISUAL_OUT {
-		// Initialize SDL
-		if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
-			panic(err)
-		}
-		defer sdl.Quit()
-
-		// Create window
-		window, err := sdl.CreateWindow(
-			"Conway's Game of Life",
-			sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
-			gridWidth, gridHeight,
-			sdl.WINDOW_SHOWN|sdl.WINDOW_RESIZABLE,
-		)
+	if *visual {
+		window, r, err := openWindow(*zoom*game.width, *zoom*game.height)
+		if err != nil {
+			if !*headlessFallback {
+				fmt.Fprintf(os.Stderr, "golife: could not open a window: %v\n", err)
+				fmt.Fprintln(os.Stderr, "On a machine without a display run with -visual=false and stream with -protocol,")
+				fmt.Fprintln(os.Stderr, "or pass -headless-fallback to do that automatically.")
+				os.Exit(1)
+			}
+			slog.Warn("could not open a window, continuing without one", "err", err)
+			*visual = false
+		} else {
+			defer sdl.Quit()
+			defer window.Destroy()
+			defer r.Destroy()
+			renderer = r
+		}
+	}
+
+	var metrics *Metrics
+	if *metricsAddr != "" {
+		metrics, err = serveMetrics(*metricsAddr)
 		if err != nil {
-			panic(err)
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+	}
+
+	var stats *StatsWriter
+	if *statsPath != "" {
+		unit, err := parseTimeUnit(*statsUnit)
+		if err == nil {
+			stats, err = NewStatsWriter(*statsPath, unit, *statsGenTime)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+		defer stats.Close()
+	}
+
+	if metrics != nil {
+		game.OnGeneration(func(g *Game, gen int) error {
+			metrics.Update(g)
+			return nil
+		})
+	}
+	if stats != nil {
+		game.OnGeneration(func(g *Game, gen int) error {
+			if stats == nil {
+				return nil
+			}
+			if err := stats.Write(g); err != nil {
+				slog.Warn("stats stopped", "err", err)
+				stats = nil
+			}
+			return nil
+		})
+	}
+	if *noise > 0 {
+		if *seed == 0 {
+			*seed = time.Now().UnixNano()
+		}
+		// Seeded apart from the board so the same -seed gives the same noise
+		game.OnGeneration(Noise(*noise, rand.New(rand.NewSource(*seed+1))))
+	}
+	if *trackShips {
+		game.OnGeneration(NewShipTracker(game).Observe)
+	}
+	if *stopOnExtinction {
+		game.OnGeneration(StopOnExtinction(game))
+	}
+	if *pngDir != "" {
+		frames, err := NewPNGFrames(*pngDir, *pngDigits, *pngStart)
+		if err == nil {
+			err = frames.Save(game, game.Generation())
 		}
-		defer window.Destroy()
-		// Create renderer
-		renderer, err = sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED)
 		if err != nil {
-			panic(err)
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
 		}
-		defer renderer.Destroy()
+		game.OnGeneration(frames.Save)
+	}
+	if *snapshotEvery > 0 {
+		snapshots := NewSnapshotter(*snapshotDir, *snapshotEvery, *snapshotKeep)
+		game.OnGeneration(snapshots.Save)
+	}
+	if *fps > 0 {
+		game.limiter = NewFrameLimiter(*fps)
+	}
+	game.startDelay = *startDelay
+	if *outputFPS > 0 {
+		game.outputPacer = NewOutputPacer(*outputFPS)
+	}
+	if *cpuPercent > 0 {
+		game.throttle = newCPUThrottle(*cpuPercent, game.workers)
+	}
+	if *latencyBudget > 0 {
+		game.latency = NewLatencyTracker(time.Duration(*latencyBudget * float64(time.Millisecond)))
+	}
+
+	// Stop cleanly on Ctrl+C so the deferred cleanup runs
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if pauseSignal != nil {
+		game.control = make(chan os.Signal, 1)
+		signal.Notify(game.control, pauseSignal, stepSignal)
 	}
 
-	for {
-		// var wg sync.WaitGroup
-		// wg.Add(2)
+	var out io.Writer = os.Stdout
+	if *listenAddr != "" {
+		server, err := Listen(*listenAddr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+		defer server.Close()
 
-		// go func() {
-		// defer wg.Done()
-		game.OutputAll(renderer)
-		// }()
+		slog.Info("waiting for a client", "addr", *listenAddr)
+		if err := server.Accept(); err != nil {
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+		protocol = server.protocol
+		out = server
+		game.OnGeneration(server.switchClient)
+	}
+	if *fifoPath != "" {
+		fifo, err := OpenFIFO(ctx, *fifoPath)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+		defer fifo.Close()
+		out = fifo
+		game.OnGeneration(fifo.switchReader)
+	}
+	if *teeSinks != "" {
+		fan := &FanOut{}
+		if *fifoPath != "" {
+			fan.Add(*fifoPath, out)
+		} else {
+			fan.Add("stdout", out)
+		}
+		if err := fan.Open(*teeSinks); err != nil {
+			fmt.Fprintln(os.Stderr, "golife:", err)
+			os.Exit(1)
+		}
+		defer fan.Close()
+		out = fan
+		// A closed stdout pipe then fails its writes like any other sink
+		// instead of killing the process
+		signal.Ignore(syscall.SIGPIPE)
+	}
 
-		// go func() {
-		// defer wg.Done()
-		game.Update()
-		// }()
+	if *outputBuffer > 0 && protocol != Off {
+		game.outputQueue = NewFrameQueue(out, *outputBuffer)
+	}
 
-		// wg.Wait()
-		game.Swap()
+	err = game.Run(ctx, out, renderer)
+	flushFPS()
+	if game.outputQueue != nil {
+		if closeErr := game.outputQueue.Close(); err == nil || errors.Is(err, context.Canceled) {
+			err = closeErr
+		}
+	}
+	if *svgPath != "" {
+		if svgErr := game.SaveSVG(*svgPath); err == nil || errors.Is(err, context.Canceled) {
+			err = svgErr
+		}
+	}
+	if *csvPath != "" {
+		if csvErr := game.SaveCSV(*csvPath); err == nil || errors.Is(err, context.Canceled) {
+			err = csvErr
+		}
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "golife:", err)
+		os.Exit(1)
 	}
 }
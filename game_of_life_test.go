@@ -0,0 +1,255 @@
+package main
+
+import (
+	"image"
+	"io"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestEnemyMargin(t *testing.T) {
+	// The middle cell has one neighbor of its own color and two of the
+	// other, three in all, so the rule alone keeps it alive
+	for _, cell := range []uint8{BLUE, ORANGE} {
+		enemy := uint8(BLUE + ORANGE - cell)
+		for _, margin := range []struct {
+			margin int
+			want   uint8
+		}{
+			{0, cell},
+			{1, DEAD},
+			{2, cell},
+		} {
+			g := makeGame(5, 5)
+			g.enemyMargin = margin.margin
+			g.grid[2][2] = cell
+			g.grid[1][2] = cell
+			g.grid[3][2] = enemy
+			g.grid[2][1] = enemy
+			if got := g.CellChange(2, 2); got != margin.want {
+				t.Errorf("cell %d, margin %d: became %d, want %d", cell, margin.margin, got, margin.want)
+			}
+		}
+	}
+}
+
+func TestEnemyMarginLeavesBirths(t *testing.T) {
+	g := makeGame(5, 5)
+	g.enemyMargin = 1
+	g.grid[1][2] = BLUE
+	g.grid[3][2] = ORANGE
+	g.grid[2][1] = ORANGE
+	if got := g.CellChange(2, 2); got != ORANGE {
+		t.Errorf("empty cell became %d, want an orange birth", got)
+	}
+}
+
+func TestBirthBias(t *testing.T) {
+	for _, c := range []struct {
+		blue, orange int
+		bias         float64
+		want         uint8
+	}{
+		// Without a bias the majority picks the color
+		{2, 1, 0, BLUE},
+		{1, 2, 0, ORANGE},
+		// A positive bias favors orange, blue must win by more than it
+		{2, 1, 1, ORANGE},
+		{2, 1, 0.5, BLUE},
+		// A negative one favors blue
+		{1, 2, -1, ORANGE},
+		{1, 2, -1.5, BLUE},
+	} {
+		g := makeGame(5, 5)
+		g.birthBias = c.bias
+		neighbors := [][2]int{{1, 1}, {1, 2}, {1, 3}}
+		for i, n := range neighbors {
+			if i < c.blue {
+				g.grid[n[0]][n[1]] = BLUE
+			} else {
+				g.grid[n[0]][n[1]] = ORANGE
+			}
+		}
+		if got := g.CellChange(2, 2); got != c.want {
+			t.Errorf("%d blue, %d orange, bias %v: born %d, want %d", c.blue, c.orange, c.bias, got, c.want)
+		}
+	}
+}
+
+func TestPackCellRoundTrip(t *testing.T) {
+	for _, cell := range []Cell{
+		{0, 0, EMPTY},
+		{PACK_MAX_COORD, PACK_MAX_COORD, PACK_MAX_STATE},
+		{PACK_MAX_COORD, 0, BLUE},
+		{0, PACK_MAX_COORD, ORANGE},
+		{1234, 567, MAX_STATE},
+	} {
+		packed := packCell(cell.X, cell.Y, cell.State)
+		if packed == END_OF_FRAME {
+			t.Errorf("%v packs to END_OF_FRAME", cell)
+		}
+		if x, y, state := unpackCell(packed); x != cell.X || y != cell.Y || state != cell.State {
+			t.Errorf("%v unpacks to %d,%d state %d", cell, x, y, state)
+		}
+	}
+}
+
+func TestCheckPacked(t *testing.T) {
+	if err := checkPacked(PACK_MAX_COORD+1, PACK_MAX_COORD+1, PACK_MAX_STATE); err != nil {
+		t.Errorf("the largest grid and state that fit: %v", err)
+	}
+	for _, c := range []struct{ width, height, maxState int }{
+		{PACK_MAX_COORD + 2, 10, MAX_STATE},
+		{10, PACK_MAX_COORD + 2, MAX_STATE},
+		{10, 10, PACK_MAX_STATE + 1},
+	} {
+		if checkPacked(c.width, c.height, c.maxState) == nil {
+			t.Errorf("%dx%d with states up to %d accepted", c.width, c.height, c.maxState)
+		}
+	}
+}
+
+func TestWriteFrameRefusesUnpackableGrid(t *testing.T) {
+	defer func(p Protocol) { protocol = p }(protocol)
+	g := makeGame(PACK_MAX_COORD+2, 1)
+	for _, p := range []Protocol{SparsePixels, SparsePixelsHeader, DeltaCells} {
+		protocol = p
+		if err := g.writeFrame(io.Discard); err == nil {
+			t.Errorf("%v wrote a grid too wide to pack", p)
+		}
+	}
+	protocol = DenseCells
+	if err := g.writeFrame(io.Discard); err != nil {
+		t.Errorf("%v needs no packing: %v", protocol, err)
+	}
+}
+
+func TestCellLifecycle(t *testing.T) {
+	// Three neighbors in a row above the middle cell, enough for a birth
+	// and a survival under the default rule
+	g := makeGame(9, 9)
+	neighbors := func(state uint8) {
+		for x := 3; x <= 5; x++ {
+			g.grid[x][3] = state
+		}
+	}
+	step := func(want uint8) {
+		t.Helper()
+		next := g.CellChange(4, 4)
+		if next != want {
+			t.Fatalf("%s became %s, want %s", stateNames[g.grid[4][4]], stateNames[next], stateNames[want])
+		}
+		g.grid[4][4] = next
+	}
+
+	g.grid[4][4] = BLUE
+	neighbors(BLUE)
+	step(BLUE)
+	neighbors(EMPTY)
+	step(DEAD)
+	// Every decay stage moves on one per generation, whatever the
+	// neighbors would give an EMPTY cell
+	neighbors(BLUE)
+	for state := DEAD + 1; state <= MAX_STATE; state++ {
+		step(uint8(state))
+	}
+	step(EMPTY)
+	step(BLUE)
+}
+
+func TestForEachLive(t *testing.T) {
+	g := makeGame(4, 3)
+	g.grid[3][0] = BLUE
+	g.grid[0][2] = DEAD
+	g.grid[1][0] = ORANGE
+	g.grid[2][1] = MAX_STATE
+	var got []Cell
+	g.ForEachLive(func(x, y int, state uint8) {
+		got = append(got, Cell{x, y, state})
+	})
+	// Row by row from the top, decaying cells included
+	want := []Cell{{1, 0, ORANGE}, {3, 0, BLUE}, {2, 1, MAX_STATE}, {0, 2, DEAD}}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestUpdateIndependentOfWorkers(t *testing.T) {
+	// Odd sizes so the columns never split evenly between workers
+	const GENERATIONS = 60
+	run := func(schedule Schedule, workers int) *Game {
+		g, err := newEmptyGame(97, 61, DoubleBuffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.fillSeeded(5, nil)
+		g.schedule = schedule
+		g.workers = workers
+		for range GENERATIONS {
+			g.Update()
+			g.Swap()
+		}
+		return g
+	}
+	for _, schedule := range []Schedule{Tiles, Static} {
+		want := run(schedule, 1)
+		for _, workers := range []int{2, runtime.NumCPU()} {
+			if !run(schedule, workers).Equal(want) {
+				t.Errorf("schedule %d: %d workers differ from one after %d generations", schedule, workers, GENERATIONS)
+			}
+		}
+	}
+}
+
+func TestMinGridSize(t *testing.T) {
+	const OK = MIN_GRID_SIZE + 10
+	for _, c := range []struct {
+		width, height int
+		ok            bool
+	}{
+		{MIN_GRID_SIZE - 1, OK, false},
+		{MIN_GRID_SIZE, OK, true},
+		{MIN_GRID_SIZE + 1, OK, true},
+		{OK, MIN_GRID_SIZE - 1, false},
+		{OK, MIN_GRID_SIZE, true},
+		{OK, MIN_GRID_SIZE + 1, true},
+	} {
+		g, err := newEmptyGame(c.width, c.height, DoubleBuffer)
+		if (err == nil) != c.ok {
+			t.Errorf("%dx%d: got error %v, want accepted %v", c.width, c.height, err, c.ok)
+			continue
+		}
+		if c.ok && (g.width != c.width || g.height != c.height) {
+			t.Errorf("%dx%d: made a %dx%d game", c.width, c.height, g.width, g.height)
+		}
+	}
+}
+
+func TestSwapAndCountMatchesDiff(t *testing.T) {
+	for _, active := range []image.Rectangle{{}, image.Rect(5, 4, 30, 25)} {
+		g, err := newEmptyGame(50, 40, DoubleBuffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.fillSeeded(2, nil)
+		g.active = active
+		prev := makeGame(g.width, g.height)
+		for gen := range 20 {
+			prev.Stamp(g, 0, 0, Overwrite)
+			g.Update()
+			got := g.SwapAndCount()
+			want := 0
+			for _, column := range g.DiffMask(prev.grid) {
+				for _, changed := range column {
+					if changed {
+						want++
+					}
+				}
+			}
+			if got != want {
+				t.Fatalf("active %v, generation %d: counted %d changes, the grids differ in %d cells", active, gen+1, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+// History keeps copies of the most recent generations in a ring buffer
+type History struct {
+	frames [][][]uint8
+	next   int // slot the next Push writes
+	count  int
+}
+
+func NewHistory(depth, width, height int) *History {
+	frames := make([][][]uint8, depth)
+	for i := range frames {
+		frames[i] = make([][]uint8, width)
+		for x := range frames[i] {
+			frames[i][x] = make([]uint8, height)
+		}
+	}
+	return &History{frames: frames}
+}
+
+// Push stores a copy of grid, replacing the oldest one once full
+func (h *History) Push(grid [][]uint8) {
+	frame := h.frames[h.next]
+	for x := range frame {
+		copy(frame[x], grid[x])
+	}
+	h.next = (h.next + 1) % len(h.frames)
+	h.count = min(h.count+1, len(h.frames))
+}
+
+// Back returns the grid pushed n pushes ago, 0 being the latest, or nil if it
+// is no longer kept. The grid is overwritten by later pushes.
+func (h *History) Back(n int) [][]uint8 {
+	if n < 0 || n >= h.count {
+		return nil
+	}
+	return h.frames[(h.next-1-n+len(h.frames))%len(h.frames)]
+}
+
+func (h *History) Len() int {
+	return h.count
+}
+
+// EnableHistory keeps the last depth generations, Swap records each one
+// (Update does in place, before overwriting it)
+func (g *Game) EnableHistory(depth int) {
+	if g.history != nil && len(g.history.frames) >= depth {
+		return
+	}
+	g.history = NewHistory(depth, g.width, g.height)
+}
+
+// DiffMask marks the cells whose state differs from prev
+func (g *Game) DiffMask(prev [][]uint8) [][]bool {
+	mask := make([][]bool, g.width)
+	for x := range g.width {
+		mask[x] = make([]bool, g.height)
+		for y := range g.height {
+			mask[x][y] = g.grid[x][y] != prev[x][y]
+		}
+	}
+	return mask
+}
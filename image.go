@@ -0,0 +1,36 @@
+package main
+
+import (
+	"image"
+	"image/color"
+)
+
+// boardImage shows a game as an image.Image, one pixel per cell in the
+// colors DensePixels writes
+type boardImage struct {
+	g      *Game
+	colors []uint32
+}
+
+// Image returns a view of the board for the image packages, so that
+// png.Encode(w, g.Image()) saves it. The view reads the grid as it is when
+// At is called, the colors are fixed when Image is.
+func (g *Game) Image() image.Image {
+	return boardImage{g: g, colors: g.colors(&pixelPalette)}
+}
+
+func (b boardImage) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (b boardImage) Bounds() image.Rectangle {
+	return image.Rect(0, 0, b.g.width, b.g.height)
+}
+
+func (b boardImage) At(x, y int) color.Color {
+	if x < 0 || x >= b.g.width || y < 0 || y >= b.g.height {
+		return color.RGBA{}
+	}
+	c := b.colors[b.g.colorIndex(x, y)]
+	return color.RGBA{R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c), A: 0xFF}
+}
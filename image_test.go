@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"math/rand"
+	"testing"
+)
+
+func TestImagePNG(t *testing.T) {
+	g, err := NewGame(7, 5, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := png.Encode(&b, g.Image()); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 7, 5) {
+		t.Fatalf("decoded as %v", img.Bounds())
+	}
+	for x := range 7 {
+		for y := range 5 {
+			c := pixelPalette[g.grid[x][y]]
+			r, green, blue, alpha := img.At(x, y).RGBA()
+			if uint8(r>>8) != uint8(c>>16) || uint8(green>>8) != uint8(c>>8) || uint8(blue>>8) != uint8(c) || alpha != 0xFFFF {
+				t.Errorf("pixel %d,%d is %v, want %06x", x, y, img.At(x, y), c)
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// InitFill fills an empty grid with a random starting board. A cell gets
+// its state from seed and its own coordinates, so the same seed gives the
+// same cells whatever the size of the grid or the order they are filled in.
+type InitFill func(g *Game, seed int64)
+
+func parseInitFill(name string) (InitFill, error) {
+	switch name {
+	case "noise":
+		return fillNoise, nil
+	case "clustered":
+		return fillClustered, nil
+	case "stripes":
+		return fillStripes, nil
+	}
+	return nil, fmt.Errorf("unknown init pattern %q, expected noise, clustered or stripes", name)
+}
+
+// newSeededGame starts a game filled by the -init pattern from seed, the
+// same cells the main board gets for that seed
+func newSeededGame(width, height int, seed int64) (*Game, error) {
+	fill, err := parseInitFill(*initPattern)
+	if err != nil {
+		return nil, err
+	}
+	game, err := newEmptyGame(width, height, gridBuffers())
+	if err != nil {
+		return nil, err
+	}
+	fill(game, seed)
+	game.resetState()
+	return game, nil
+}
+
+// Set changes the state of the cell at x, y, which must be on the grid,
+// keeping the population counts in step and restarting its age
+func (g *Game) Set(x, y int, state uint8) {
+	g.population[g.grid[x][y]]--
+	g.population[state]++
+	g.grid[x][y] = state
+	if g.age != nil {
+		g.age[x][y] = 0
+	}
+}
+
+// cellRand returns a random number that depends only on seed and x, y. The
+// seed is mixed on its own first, so seeds that differ in a few bits do not
+// give boards that only differ by swapped rows.
+func cellRand(seed int64, x, y int) uint64 {
+	return splitmix64(splitmix64(uint64(seed)) ^ uint64(uint32(x))<<32 ^ uint64(uint32(y)))
+}
+
+// splitmix64 is one step of the splitmix64 generator, scrambling every bit
+// of z into every bit of the result
+func splitmix64(z uint64) uint64 {
+	z += 0x9E3779B97F4A7C15
+	z = (z ^ z>>30) * 0xBF58476D1CE4E5B9
+	z = (z ^ z>>27) * 0x94D049BB133111EB
+	return z ^ z>>31
+}
+
+// cellFloat returns a number in [0, 1) that depends only on seed and x, y
+func cellFloat(seed int64, x, y int) float64 {
+	return float64(cellRand(seed, x, y)>>11) / (1 << 53)
+}
+
+// FillRandom picks EMPTY, BLUE, ORANGE or DEAD alike for every cell mask
+// returns true for, the others keep their state. A nil mask takes every cell.
+// Only one number is drawn from r, the state of a cell depends on it and
+// the cell's coordinates alone.
+func (g *Game) FillRandom(r *rand.Rand, mask func(x, y int) bool) {
+	g.fillSeeded(r.Int63(), mask)
+}
+
+// fillSeeded is FillRandom with the seed given
+func (g *Game) fillSeeded(seed int64, mask func(x, y int) bool) {
+	for x := range g.width {
+		for y := range g.height {
+			if mask == nil || mask(x, y) {
+				g.Set(x, y, uint8(cellRand(seed, x, y)>>62))
+			}
+		}
+	}
+}
+
+// fillNoise fills every cell alike
+func fillNoise(g *Game, seed int64) {
+	g.fillSeeded(seed, nil)
+}
+
+// fillClustered paints discs of one color with radius between half of
+// -cluster-radius and all of it until their areas add up to half the grid.
+// Cells in a disc are live with chance -init-density, later discs paint over
+// earlier ones and the rest of the grid stays EMPTY. Where the discs go
+// depends on the grid size, whether a cell in one is live does not.
+func fillClustered(g *Game, seed int64) {
+	r := rand.New(rand.NewSource(seed))
+	radius := max(1, *clusterRadius)
+	area := 0
+	for area < g.width*g.height/2 {
+		cx, cy := r.Intn(g.width), r.Intn(g.height)
+		rad := radius/2 + r.Intn(radius-radius/2+1)
+		state := uint8(BLUE + r.Intn(2))
+		for dx := -rad; dx <= rad; dx++ {
+			for dy := -rad; dy <= rad; dy++ {
+				if dx*dx+dy*dy > rad*rad {
+					continue
+				}
+				x, y, ok := g.resolvePlaced(cx+dx, cy+dy)
+				if !ok {
+					continue
+				}
+				area++
+				if cellFloat(seed, x, y) < *initDensity {
+					g.Set(x, y, state)
+				} else {
+					g.Set(x, y, EMPTY)
+				}
+			}
+		}
+	}
+}
+
+// fillStripes alternates BLUE and ORANGE vertical bands -stripe-width cells
+// wide, cells in them live with chance -init-density
+func fillStripes(g *Game, seed int64) {
+	band := max(1, *stripeWidth)
+	for x := range g.width {
+		state := uint8(BLUE + x/band%2)
+		for y := range g.height {
+			if cellFloat(seed, x, y) < *initDensity {
+				g.Set(x, y, state)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestSeedIndependentOfSize(t *testing.T) {
+	for _, fill := range []InitFill{fillNoise, fillStripes} {
+		small := makeGame(64, 64)
+		big := makeGame(128, 96)
+		fill(small, 42)
+		fill(big, 42)
+		for x := range small.width {
+			for y := range small.height {
+				if small.grid[x][y] != big.grid[x][y] {
+					t.Fatalf("cell %d,%d differs between grid sizes", x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestSeedIndependentOfWorkers(t *testing.T) {
+	ref := makeGame(100, 80)
+	ref.fillSeeded(7, nil)
+	for _, workers := range []int{1, 3, 8} {
+		// Each worker fills every workers-th column of its own board, the
+		// columns are put together afterwards
+		parts := make([]*Game, workers)
+		var wg sync.WaitGroup
+		for i := range workers {
+			parts[i] = makeGame(100, 80)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				parts[i].fillSeeded(7, func(x, y int) bool { return x%workers == i })
+			}()
+		}
+		wg.Wait()
+		g := makeGame(100, 80)
+		for x := range g.width {
+			copy(g.grid[x], parts[x%workers].grid[x])
+		}
+		if !EqualGrid(g.grid, ref.grid) {
+			t.Errorf("%d workers filled a different board", workers)
+		}
+	}
+}
+
+func TestFillRandomReproducible(t *testing.T) {
+	a, b := makeGame(50, 40), makeGame(50, 40)
+	a.FillRandom(rand.New(rand.NewSource(5)), nil)
+	b.FillRandom(rand.New(rand.NewSource(5)), nil)
+	if !a.Equal(b) {
+		t.Error("the same seed filled different boards")
+	}
+}
+
+func TestNearbySeedsDiffer(t *testing.T) {
+	// Seeds one bit apart once gave the same board with rows swapped in pairs
+	for _, seed := range []int64{100, 101, 1 << 40} {
+		a, b := makeGame(40, 40), makeGame(40, 40)
+		a.fillSeeded(seed, nil)
+		b.fillSeeded(seed^1, nil)
+		same := 0
+		for x := range 40 {
+			for y := range 40 {
+				if a.grid[x][y] == b.grid[x][y^1] {
+					same++
+				}
+			}
+		}
+		// Independent boards of four states agree on about a quarter
+		if same > 40*40/2 {
+			t.Errorf("seeds %d and %d agree on %d of 1600 cells with rows swapped", seed, seed^1, same)
+		}
+	}
+}
+
+func TestFillRandomMask(t *testing.T) {
+	// Every cell starts in a state FillRandom never picks, so the cells it
+	// skipped are told apart from the ones it filled
+	g := makeGame(40, 30)
+	for x := range g.width {
+		for y := range g.height {
+			g.grid[x][y] = DEAD + 1
+		}
+	}
+	g.countPopulation()
+	black := func(x, y int) bool { return (x+y)%2 == 0 }
+	g.FillRandom(rand.New(rand.NewSource(1)), black)
+
+	var picked [DEAD + 1]int
+	for x := range g.width {
+		for y := range g.height {
+			state := g.grid[x][y]
+			switch {
+			case !black(x, y) && state != DEAD+1:
+				t.Fatalf("cell %d,%d outside the mask was filled with %d", x, y, state)
+			case black(x, y) && state > DEAD:
+				t.Fatalf("cell %d,%d inside the mask was left at %d", x, y, state)
+			case black(x, y):
+				picked[state]++
+			}
+		}
+	}
+	for state, n := range picked {
+		if n == 0 {
+			t.Errorf("state %d never picked in %d cells", state, g.width*g.height/2)
+		}
+	}
+
+	// The population kept up with every cell set
+	counted := g.population
+	g.countPopulation()
+	if counted != g.population {
+		t.Errorf("population %v, counted again %v", counted, g.population)
+	}
+}
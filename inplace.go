@@ -0,0 +1,80 @@
+package main
+
+// GridBuffers decides where Update writes the next generation
+type GridBuffers int
+
+const (
+	DoubleBuffer GridBuffers = iota // into nextGrid, swapped with grid
+	ReuseBuffer                     // into grid itself, without a nextGrid
+)
+
+// gridBuffers is the strategy -grid-buffer-reuse asks for
+func gridBuffers() GridBuffers {
+	if *gridBufferReuse {
+		return ReuseBuffer
+	}
+	return DoubleBuffer
+}
+
+// heldColumn is the next generation of a column that another range may
+// still read the current states of
+type heldColumn struct {
+	x     int
+	cells []uint8
+}
+
+// updateColumnsInPlace is updateColumns for -grid-buffer-reuse, writing the
+// next generation into grid itself. Column x is only written once column
+// x+1 is done, the last one that reads it, so a range keeps just one column
+// in a rolling buffer. The first and last column of the range are read by
+// the ranges next to it, they are added to held and written by Update after
+// every range is done.
+func (g *Game) updateColumnsInPlace(x0, x1, y0, y1 int, count, old *[MAX_STATE + 1]int, hist *[9]int, changed *int, held *[]heldColumn) {
+	var buffers [2][]uint8
+	free := 0 // the buffer not holding the pending column
+	var pending []uint8
+	pendingX := -1
+	for x := x0; x < x1; x++ {
+		edge := x == x0 || x == x1-1
+		var next []uint8
+		if edge {
+			next = make([]uint8, y1-y0)
+		} else {
+			if buffers[free] == nil {
+				buffers[free] = make([]uint8, y1-y0)
+			}
+			next = buffers[free]
+		}
+
+		for y := y0; y < y1; y++ {
+			cell := g.cellChange(x, y, hist)
+			next[y-y0] = cell
+			count[cell]++
+			if old != nil {
+				old[g.grid[x][y]]++
+			}
+			if cell != g.grid[x][y] {
+				*changed++
+			}
+			// Column x still holds the current generation, ages and aux
+			// values are kept in two buffers as usual
+			if g.age != nil {
+				g.nextAge[x][y] = g.nextCellAge(x, y, cell)
+			}
+			if g.aux != nil {
+				g.nextAux[x][y] = g.nextCellAux(x, y, cell)
+			}
+		}
+
+		if pendingX >= 0 {
+			copy(g.grid[pendingX][y0:], pending)
+			pending, pendingX = nil, -1
+		}
+		if edge {
+			*held = append(*held, heldColumn{x, next})
+		} else {
+			pending, pendingX = next, x
+			free ^= 1
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"image"
+	"slices"
+	"testing"
+)
+
+func TestInPlaceMatchesDoubleBuffer(t *testing.T) {
+	newGame := func(buffers GridBuffers, edge EdgeMode, schedule Schedule, workers int, active image.Rectangle) *Game {
+		g, err := newEmptyGame(57, 41, buffers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.fillSeeded(11, nil)
+		g.EnableAge()
+		g.EnableAux(auxQuiet)
+		g.edge, g.schedule, g.workers, g.active = edge, schedule, workers, active
+		return g
+	}
+
+	for _, edge := range []EdgeMode{Toroidal, Bounded, Reflecting, WrapX} {
+		for _, schedule := range []Schedule{Tiles, Static} {
+			// 64 workers is more than there are columns
+			for _, workers := range []int{1, 2, 5, 64} {
+				for _, active := range []image.Rectangle{{}, image.Rect(3, 4, 40, 30)} {
+					double := newGame(DoubleBuffer, edge, schedule, workers, active)
+					reused := newGame(ReuseBuffer, edge, schedule, workers, active)
+					if double.nextGrid == nil || reused.nextGrid != nil {
+						t.Fatal("the buffer strategy did not decide whether nextGrid is allocated")
+					}
+					for gen := range 30 {
+						double.Update()
+						doubleChanged := double.SwapAndCount()
+						reused.Update()
+						reusedChanged := reused.SwapAndCount()
+						if !double.Equal(reused) || double.population != reused.population || doubleChanged != reusedChanged {
+							t.Fatalf("edge %d, schedule %d, %d workers, active %v: generation %d differs in place",
+								edge, schedule, workers, active, gen+1)
+						}
+						if !sameAges(double, reused) || !EqualGrid(double.aux, reused.aux) {
+							t.Fatalf("edge %d, schedule %d, %d workers, active %v: ages or aux of generation %d differ in place",
+								edge, schedule, workers, active, gen+1)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestInPlaceHistory(t *testing.T) {
+	var games [2]*Game
+	for i, buffers := range []GridBuffers{DoubleBuffer, ReuseBuffer} {
+		g, err := newEmptyGame(30, 20, buffers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.fillSeeded(4, nil)
+		g.EnableHistory(3)
+		games[i] = g
+	}
+	double, reused := games[0], games[1]
+	prev := makeGame(reused.width, reused.height)
+	for gen := range 10 {
+		prev.Stamp(reused, 0, 0, Overwrite)
+		double.Update()
+		double.Swap()
+		reused.Update()
+		reused.Swap()
+		if !EqualGrid(reused.history.Back(0), prev.grid) {
+			t.Fatalf("generation %d: the latest history entry is not the generation before it", gen+1)
+		}
+		for n := range 3 {
+			if !EqualGrid(double.history.Back(n), reused.history.Back(n)) {
+				t.Fatalf("generation %d: history entry %d differs in place", gen+1, n)
+			}
+		}
+	}
+}
+
+func sameAges(a, b *Game) bool {
+	for x := range a.age {
+		if !slices.Equal(a.age[x], b.age[x]) {
+			return false
+		}
+	}
+	return true
+}
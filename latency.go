@@ -0,0 +1,47 @@
+package main
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Generations summarized in each latency report
+const LATENCY_WINDOW = 100
+
+// LatencyTracker collects how long Update takes and reports every
+// LATENCY_WINDOW generations when any of them went over the budget.
+type LatencyTracker struct {
+	budget  time.Duration
+	samples []time.Duration
+
+	// Of the last full window
+	Max, P99 time.Duration
+}
+
+func NewLatencyTracker(budget time.Duration) *LatencyTracker {
+	return &LatencyTracker{budget: budget, samples: make([]time.Duration, 0, LATENCY_WINDOW)}
+}
+
+// Record adds the duration of one Update
+func (l *LatencyTracker) Record(d time.Duration) {
+	l.samples = append(l.samples, d)
+	if len(l.samples) < LATENCY_WINDOW {
+		return
+	}
+
+	slices.Sort(l.samples)
+	l.Max = l.samples[len(l.samples)-1]
+	l.P99 = l.samples[(len(l.samples)*99-1)/100]
+	over := 0
+	for _, d := range l.samples {
+		if d > l.budget {
+			over++
+		}
+	}
+	if over > 0 {
+		slog.Warn("Update went over the latency budget", "budget", l.budget, "over", over, "of", len(l.samples),
+			"max", l.Max.Round(time.Microsecond), "p99", l.P99.Round(time.Microsecond))
+	}
+	l.samples = l.samples[:0]
+}
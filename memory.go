@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// MemoryEstimate is roughly how many bytes a run allocates for its buffers
+type MemoryEstimate struct {
+	Grids        int // grid, nextGrid and the optional per cell layers
+	History      int
+	Framebuffers int // window drawing and pixel output
+}
+
+func (m MemoryEstimate) Total() int {
+	return m.Grids + m.History + m.Framebuffers
+}
+
+// estimateMemory works out the buffers the flags ask for on a width by height
+// grid, before any of them is allocated
+func estimateMemory(width, height int) MemoryEstimate {
+	cells := width * height
+	var m MemoryEstimate
+
+	m.Grids = 2 * cells
+	if *gridBufferReuse {
+		m.Grids = cells
+	}
+	if colorMode == ColorAge {
+		m.Grids += 2 * 2 * cells
+	}
+	if colorMode == ColorAux {
+		m.Grids += 2 * cells
+	}
+	if *pins != "" || *pinFile != "" {
+		m.Grids += cells
+	}
+	if protocol == DeltaCells || protocol == DeltaBitmap {
+		m.Grids += cells
+	}
+
+	depth := *historyDepth
+	if colorMode == ColorDiff {
+		depth = max(depth, 1)
+	}
+	m.History = depth * cells
+
+	if *visual {
+		switch renderMode {
+		case RenderTexture:
+			m.Framebuffers += 4 * cells
+		default:
+			m.Framebuffers += 8 * cells // one sdl.Point per cell at worst
+		}
+	}
+	if *visual && *persistence > 0 {
+		m.Framebuffers += 4 * cells
+	}
+	if protocol == DensePixels || protocol == DensePixels16 {
+		m.Framebuffers += *chunkSize
+	}
+	return m
+}
+
+// checkMemory reports the estimate for a grid and refuses it when it is over -max-mem
+func checkMemory(width, height int) error {
+	m := estimateMemory(width, height)
+	slog.Info("estimated memory", "grids", mib(m.Grids), "history", mib(m.History),
+		"framebuffers", mib(m.Framebuffers), "total", mib(m.Total()))
+
+	if *maxMem > 0 && m.Total() > *maxMem<<20 {
+		return fmt.Errorf("a %dx%d grid needs about %s, more than -max-mem %d MiB", width, height, mib(m.Total()), *maxMem)
+	}
+	return nil
+}
+
+func mib(bytes int) string {
+	return fmt.Sprintf("%.1f MiB", float64(bytes)/(1<<20))
+}
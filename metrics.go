@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Metrics serves the state of the run as JSON. The simulation loop copies its
+// numbers in with Update, so requests never touch the game itself.
+type Metrics struct {
+	mu         sync.Mutex
+	start      time.Time
+	generation int
+	fps        int
+	population [MAX_STATE + 1]int
+}
+
+// serveMetrics starts answering HTTP requests on addr in the background.
+// The run goes on without them when serving stops.
+func serveMetrics(addr string) (*Metrics, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("metrics: %w", err)
+	}
+
+	m := &Metrics{start: time.Now()}
+	go func() {
+		err := http.Serve(listener, m)
+		slog.Error("metrics server stopped", "addr", listener.Addr(), "err", err)
+	}()
+	return m, nil
+}
+
+func (m *Metrics) Update(g *Game) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.generation = g.Generation()
+	m.fps = int(fpsLast.Load())
+	m.population = g.population
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	report := struct {
+		Generation    int            `json:"generation"`
+		FPS           int            `json:"fps"`
+		Population    map[string]int `json:"population"`
+		UptimeSeconds float64        `json:"uptime_seconds"`
+	}{
+		Generation:    m.generation,
+		FPS:           m.fps,
+		Population:    make(map[string]int, len(m.population)),
+		UptimeSeconds: time.Since(m.start).Seconds(),
+	}
+	for state, n := range m.population {
+		report.Population[stateNames[state]] = n
+	}
+	m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetricsReport(t *testing.T) {
+	g := makeGame(10, 10)
+	g.grid[1][1] = BLUE
+	g.grid[2][2] = BLUE
+	g.grid[3][3] = ORANGE
+	g.countPopulation()
+	g.generation = 42
+
+	m := &Metrics{}
+	m.Update(g)
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	var report struct {
+		Generation int            `json:"generation"`
+		Population map[string]int `json:"population"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Generation != 42 || report.Population["blue"] != 2 || report.Population["orange"] != 1 || report.Population["empty"] != 97 {
+		t.Errorf("reported %+v", report)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("content type %q", ct)
+	}
+}
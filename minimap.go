@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Window pixels between the minimap and the edges of the window
+const MINIMAP_INSET = 8
+
+var (
+	minimapFrame = rgba(0x33, 0x33, 0x33)
+	minimapView  = rgba(0xFF, 0x00, 0x33)
+)
+
+// checkMinimapCorner accepts the corners -minimap-corner can name
+func checkMinimapCorner(corner string) error {
+	switch corner {
+	case "top-left", "top-right", "bottom-left", "bottom-right":
+		return nil
+	}
+	return fmt.Errorf("unknown minimap corner %q, expected top-left, top-right, bottom-left or bottom-right", corner)
+}
+
+// drawView draws the part of the board the window shows, starting at the
+// panned position, then the minimap and the neighbor histogram over it. The
+// tile preview replaces them all.
+func (g *Game) drawView(renderer *sdl.Renderer) {
+	winW, winH, err := renderer.GetOutputSize()
+	if err != nil {
+		g.drawBoard(renderer)
+		return
+	}
+	if g.tilePreview {
+		g.drawTiles(renderer, winW, winH)
+		return
+	}
+
+	z := *zoom
+	visibleW, visibleH := min(g.width, int(winW)/z), min(g.height, int(winH)/z)
+	g.viewX = max(0, min(g.viewX, g.width-visibleW))
+	g.viewY = max(0, min(g.viewY, g.height-visibleH))
+
+	if g.viewX == 0 && g.viewY == 0 {
+		g.drawBoard(renderer)
+	} else {
+		renderer.SetViewport(&sdl.Rect{X: int32(-g.viewX * z), Y: int32(-g.viewY * z), W: int32(g.width * z), H: int32(g.height * z)})
+		g.drawBoard(renderer)
+		renderer.SetViewport(nil)
+	}
+
+	if *minimapSize > 0 && !g.minimapHidden {
+		g.drawMinimap(renderer, int(winW), int(winH), visibleW, visibleH)
+	}
+	if g.neighborHist != nil && !g.neighborHistHidden {
+		g.drawNeighborHist(renderer, int(winW), int(winH))
+	}
+}
+
+// drawMinimap draws the whole board shrunk to -minimap pixels on its longer
+// side in a corner of the window, with a rectangle around the part on screen
+func (g *Game) drawMinimap(renderer *sdl.Renderer, winW, winH, visibleW, visibleH int) {
+	k := max(1, (max(g.width, g.height)+*minimapSize-1) / *minimapSize)
+	w, h := g.downsampledSize(k, 0)
+
+	x0, y0 := MINIMAP_INSET, MINIMAP_INSET
+	switch *minimapCorner {
+	case "top-right":
+		x0 = winW - w - MINIMAP_INSET
+	case "bottom-left":
+		y0 = winH - h - MINIMAP_INSET
+	case "bottom-right":
+		x0, y0 = winW-w-MINIMAP_INSET, winH-h-MINIMAP_INSET
+	}
+
+	setDrawColor(renderer, minimapFrame)
+	renderer.FillRect(&sdl.Rect{X: int32(x0 - 1), Y: int32(y0 - 1), W: int32(w + 2), H: int32(h + 2)})
+	setDrawColor(renderer, windowPalette[EMPTY])
+	renderer.FillRect(&sdl.Rect{X: int32(x0), Y: int32(y0), W: int32(w), H: int32(h)})
+
+	colors := g.colors(&windowPalette)
+	counts := make([]int, (MAX_STATE+1)*len(colors))
+	points := make([][]sdl.Point, len(colors))
+	for bx := range w {
+		for by := range h {
+			i := g.blockColor(bx, by, k, 0, counts)
+			if colors[i] != colors[EMPTY] {
+				points[i] = append(points[i], sdl.Point{X: int32(x0 + bx), Y: int32(y0 + by)})
+			}
+		}
+	}
+	for i, p := range points {
+		if len(p) > 0 {
+			setDrawColor(renderer, colors[i])
+			inBatches(p, renderer.DrawPoints)
+		}
+	}
+
+	setDrawColor(renderer, minimapView)
+	renderer.DrawRect(&sdl.Rect{
+		X: int32(x0 + g.viewX/k), Y: int32(y0 + g.viewY/k),
+		W: int32(max(1, (visibleW+k-1)/k)), H: int32(max(1, (visibleH+k-1)/k)),
+	})
+}
+
+// pan moves the part of the board the window shows by a sixteenth of the
+// board per step, drawView keeps it on the board
+func (g *Game) pan(dx, dy int) {
+	g.viewX += dx * max(1, g.width/16)
+	g.viewY += dy * max(1, g.height/16)
+}
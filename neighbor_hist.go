@@ -0,0 +1,44 @@
+package main
+
+import "github.com/veandco/go-sdl2/sdl"
+
+// Size of the -neighbor-hist chart in window pixels
+const (
+	HIST_BAR_WIDTH = 8
+	HIST_HEIGHT    = 64
+)
+
+// Behind the bars, grey so the darkest and lightest neighbor colors both show
+var histBackground = rgba(0x80, 0x80, 0x80)
+
+// drawNeighborHist draws one bar per live neighbor count, colored like
+// -color neighbors and scaled to the tallest, in the bottom left corner of
+// the window, or the bottom right when the minimap is in the bottom left
+func (g *Game) drawNeighborHist(renderer *sdl.Renderer, winW, winH int) {
+	w := len(g.neighborHist)*(HIST_BAR_WIDTH+1) + 1
+	x0, y0 := MINIMAP_INSET, winH-HIST_HEIGHT-MINIMAP_INSET
+	if *minimapSize > 0 && !g.minimapHidden && *minimapCorner == "bottom-left" {
+		x0 = winW - w - MINIMAP_INSET
+	}
+
+	setDrawColor(renderer, minimapFrame)
+	renderer.FillRect(&sdl.Rect{X: int32(x0 - 1), Y: int32(y0 - 1), W: int32(w + 2), H: HIST_HEIGHT + 2})
+	setDrawColor(renderer, histBackground)
+	renderer.FillRect(&sdl.Rect{X: int32(x0), Y: int32(y0), W: int32(w), H: HIST_HEIGHT})
+
+	tallest := 1
+	for _, cells := range g.neighborHist {
+		tallest = max(tallest, cells)
+	}
+	for n, cells := range g.neighborHist {
+		if cells == 0 {
+			continue
+		}
+		h := max(1, cells*HIST_HEIGHT/tallest)
+		setDrawColor(renderer, neighborGradient[n])
+		renderer.FillRect(&sdl.Rect{
+			X: int32(x0 + 1 + n*(HIST_BAR_WIDTH+1)), Y: int32(y0 + HIST_HEIGHT - h),
+			W: HIST_BAR_WIDTH, H: int32(h),
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+// countedHist is the histogram worked out the slow way, one CountNeighbors
+// per cell
+func countedHist(g *Game) [9]int {
+	var hist [9]int
+	for x := range g.width {
+		for y := range g.height {
+			blue_count, orange_count := g.CountNeighbors(x, y)
+			hist[blue_count+orange_count]++
+		}
+	}
+	return hist
+}
+
+func TestNeighborHistMatchesCount(t *testing.T) {
+	for _, buffers := range []GridBuffers{DoubleBuffer, ReuseBuffer} {
+		for _, workers := range []int{1, 3} {
+			g, err := newEmptyGame(57, 41, buffers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.fillSeeded(5, nil)
+			g.workers = workers
+			g.neighborHist = new([9]int)
+			for gen := range 10 {
+				want := countedHist(g)
+				g.Update()
+				if *g.neighborHist != want {
+					t.Fatalf("in place %v, %d workers, generation %d: counted %v during the update, want %v",
+						buffers == ReuseBuffer, workers, gen+1, *g.neighborHist, want)
+				}
+				g.Swap()
+			}
+
+			// Hidden, the last histogram is left as it was
+			g.neighborHistHidden = true
+			before := *g.neighborHist
+			g.Update()
+			if *g.neighborHist != before {
+				t.Errorf("in place %v, %d workers: the hidden histogram was counted", buffers == ReuseBuffer, workers)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import "fmt"
+
+// Neighborhood decides which surrounding cells CountNeighbors looks at
+type Neighborhood int
+
+const (
+	Moore      Neighborhood = iota // all 8 surrounding cells
+	VonNeumann                     // only the 4 orthogonal ones
+)
+
+// forEachNeighbor calls fn with the coordinates of every neighbor of x, y in
+// the game's neighborhood, after the edge mode is applied. Neighbors off a
+// non-wrapping edge are skipped.
+func (g *Game) forEachNeighbor(x, y int, fn func(nx, ny int)) {
+	for _, d := range neighborOffsets[g.neighborhood] {
+		nx, ny := x+d[0], y+d[1]
+		if uint(nx) >= uint(g.width) || uint(ny) >= uint(g.height) {
+			var ok bool
+			if nx, ny, ok = g.resolveOutside(nx, ny); !ok {
+				continue
+			}
+		}
+		fn(nx, ny)
+	}
+}
+
+// Offsets of the cells each neighborhood counts
+var neighborOffsets = [...][][2]int{
+	Moore:      {{-1, -1}, {-1, 0}, {-1, 1}, {0, -1}, {0, 1}, {1, -1}, {1, 0}, {1, 1}},
+	VonNeumann: {{-1, 0}, {0, -1}, {0, 1}, {1, 0}},
+}
+
+func parseNeighborhood(name string) (Neighborhood, error) {
+	switch name {
+	case "moore":
+		return Moore, nil
+	case "vonneumann":
+		return VonNeumann, nil
+	}
+	return Moore, fmt.Errorf("unknown neighborhood %q, expected moore or vonneumann", name)
+}
@@ -0,0 +1,132 @@
+package main
+
+import "testing"
+
+// A 6x5 torus, so the wrapped neighbors of a corner or edge cell are never
+// its direct ones
+const (
+	TORUS_W = 6
+	TORUS_H = 5
+)
+
+func TestCountNeighborsWrapsCorners(t *testing.T) {
+	last, bottom := TORUS_W-1, TORUS_H-1
+	corners := []struct {
+		name string
+		x, y int
+		// The three neighbors across the seams, on the opposite side,
+		// above or below and diagonally across
+		across [3][2]int
+	}{
+		{"top left", 0, 0, [3][2]int{{last, 0}, {0, bottom}, {last, bottom}}},
+		{"top right", last, 0, [3][2]int{{0, 0}, {last, bottom}, {0, bottom}}},
+		{"bottom left", 0, bottom, [3][2]int{{last, bottom}, {0, 0}, {last, 0}}},
+		{"bottom right", last, bottom, [3][2]int{{0, bottom}, {last, 0}, {0, 0}}},
+	}
+	for _, corner := range corners {
+		g := makeGame(TORUS_W, TORUS_H)
+		g.grid[corner.across[0][0]][corner.across[0][1]] = BLUE
+		g.grid[corner.across[1][0]][corner.across[1][1]] = ORANGE
+		g.grid[corner.across[2][0]][corner.across[2][1]] = BLUE
+		if blue, orange := g.CountNeighbors(corner.x, corner.y); blue != 2 || orange != 1 {
+			t.Errorf("%s corner: %d blue and %d orange neighbors, want 2 and 1", corner.name, blue, orange)
+		}
+	}
+}
+
+func TestCountNeighborsWrapsEdges(t *testing.T) {
+	last, bottom := TORUS_W-1, TORUS_H-1
+	edges := []struct {
+		name string
+		x, y int
+		// The cells across the seam in the neighboring columns or rows
+		across [3][2]int
+	}{
+		{"top", 2, 0, [3][2]int{{1, bottom}, {2, bottom}, {3, bottom}}},
+		{"bottom", 2, bottom, [3][2]int{{1, 0}, {2, 0}, {3, 0}}},
+		{"left", 0, 2, [3][2]int{{last, 1}, {last, 2}, {last, 3}}},
+		{"right", last, 2, [3][2]int{{0, 1}, {0, 2}, {0, 3}}},
+	}
+	for _, edge := range edges {
+		g := makeGame(TORUS_W, TORUS_H)
+		for i, cell := range edge.across {
+			g.grid[cell[0]][cell[1]] = uint8(BLUE + i%2)
+		}
+		if blue, orange := g.CountNeighbors(edge.x, edge.y); blue != 2 || orange != 1 {
+			t.Errorf("%s edge: %d blue and %d orange neighbors, want 2 and 1", edge.name, blue, orange)
+		}
+
+		// Bounded, the same cells are off the board
+		g.edge = Bounded
+		if blue, orange := g.CountNeighbors(edge.x, edge.y); blue != 0 || orange != 0 {
+			t.Errorf("%s edge, bounded: %d blue and %d orange neighbors, want none", edge.name, blue, orange)
+		}
+	}
+}
+
+func TestCountNeighborsAllAround(t *testing.T) {
+	// Every cell of the torus live but one: each cell sees all 8 of its
+	// neighbors, minus the empty one when it is next to it
+	g := makeGame(TORUS_W, TORUS_H)
+	for x := range TORUS_W {
+		for y := range TORUS_H {
+			g.grid[x][y] = ORANGE
+		}
+	}
+	g.grid[0][0] = EMPTY
+	for x := range TORUS_W {
+		for y := range TORUS_H {
+			want := 8
+			dx, dy := min(x, TORUS_W-x), min(y, TORUS_H-y)
+			if dx <= 1 && dy <= 1 && (dx != 0 || dy != 0) {
+				want = 7
+			}
+			if blue, orange := g.CountNeighbors(x, y); blue != 0 || orange != want {
+				t.Errorf("cell %d,%d: %d orange neighbors, want %d", x, y, orange, want)
+			}
+		}
+	}
+}
+
+func TestVonNeumannIgnoresDiagonals(t *testing.T) {
+	g := makeGame(TORUS_W, TORUS_H)
+	g.neighborhood = VonNeumann
+	for _, d := range [4][2]int{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}} {
+		g.grid[2+d[0]][2+d[1]] = BLUE
+	}
+	if blue, orange := g.CountNeighbors(2, 2); blue != 0 || orange != 0 {
+		t.Errorf("diagonal cells counted: %d blue and %d orange neighbors, want none", blue, orange)
+	}
+	g.grid[2][1] = ORANGE
+	g.grid[3][2] = BLUE
+	if blue, orange := g.CountNeighbors(2, 2); blue != 1 || orange != 1 {
+		t.Errorf("%d blue and %d orange orthogonal neighbors, want 1 and 1", blue, orange)
+	}
+}
+
+func TestVonNeumannCross(t *testing.T) {
+	// Under B2/S1 with orthogonal neighbors only, the middle of a cross
+	// has 4 and dies, each arm has 1 and survives, and each cell between
+	// two arms has 2 and is born, leaving a ring around the middle. With
+	// all 8 neighbors the arms would have 3 and die.
+	g, err := newEmptyGame(9, 9, DoubleBuffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.rule = mustParseRule("B2/S1")
+	g.neighborhood = VonNeumann
+	g.SetClassic(true)
+	for _, c := range [5][2]int{{4, 4}, {3, 4}, {5, 4}, {4, 3}, {4, 5}} {
+		g.Set(c[0], c[1], BLUE)
+	}
+	g.Update()
+	g.Swap()
+	for x := range g.width {
+		for y := range g.height {
+			ring := x >= 3 && x <= 5 && y >= 3 && y <= 5 && (x != 4 || y != 4)
+			if live := g.grid[x][y] == BLUE; live != ring {
+				t.Errorf("cell %d,%d live %v, want %v", x, y, live, ring)
+			}
+		}
+	}
+}
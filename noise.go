@@ -0,0 +1,29 @@
+package main
+
+import "math/rand"
+
+// Noise returns a GenerationFunc that turns about rate of all cells into
+// random BLUE or ORANGE cells after every generation. Pinned cells are left alone.
+func Noise(rate float64, r *rand.Rand) GenerationFunc {
+	return func(g *Game, gen int) error {
+		expected := rate * float64(g.width*g.height)
+		n := int(expected)
+		if r.Float64() < expected-float64(n) {
+			n++
+		}
+		for range n {
+			x, y := r.Intn(g.width), r.Intn(g.height)
+			if g.pinned != nil && g.pinned[x][y] != EMPTY {
+				continue
+			}
+			state := uint8(BLUE + r.Intn(2))
+			g.population[g.grid[x][y]]--
+			g.population[state]++
+			g.grid[x][y] = state
+			if g.age != nil {
+				g.age[x][y] = 0
+			}
+		}
+		return nil
+	}
+}
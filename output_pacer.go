@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"time"
+)
+
+// OutputPacer writes frames at a fixed rate whatever the speed of the
+// simulation. Each generation is written as many times as output frames fell
+// due since the previous write: more than once (repeated) when the simulation
+// is slower than the rate, not at all (dropped) when it is faster.
+type OutputPacer struct {
+	interval time.Duration
+	start    time.Time
+	written  int64
+	frame    bytes.Buffer
+
+	Repeated, Dropped int
+}
+
+func NewOutputPacer(fps float64) *OutputPacer {
+	return &OutputPacer{interval: time.Duration(float64(time.Second) / fps)}
+}
+
+// Write writes the frame made by write to w as often as the rate asks for now
+func (p *OutputPacer) Write(w io.Writer, write func(io.Writer) error) error {
+	now := time.Now()
+	if p.start.IsZero() {
+		p.start = now
+	}
+	due := int64(now.Sub(p.start)/p.interval) + 1 - p.written
+	if due <= 0 {
+		p.Dropped++
+		return nil
+	}
+
+	// Built once, repeats are copies of the same bytes
+	p.frame.Reset()
+	if err := write(&p.frame); err != nil {
+		return err
+	}
+	for range due {
+		if _, err := w.Write(p.frame.Bytes()); err != nil {
+			return err
+		}
+	}
+	p.written += due
+	p.Repeated += int(due - 1)
+	fpsOutputCounter += int(due)
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// FrameQueue writes frames to a writer on its own goroutine, so a slow
+// reader does not hold up the simulation or the window. Frames are encoded
+// from the grid before Write returns, so they show the same generation as
+// the window. When depth frames are already waiting the new frame is dropped
+// without being encoded, which keeps DeltaCells frames relative to the last
+// frame actually queued.
+type FrameQueue struct {
+	frames chan *bytes.Buffer
+	free   chan *bytes.Buffer
+	done   chan struct{}
+
+	mu  sync.Mutex
+	err error
+
+	Dropped int
+}
+
+func NewFrameQueue(out io.Writer, depth int) *FrameQueue {
+	q := &FrameQueue{
+		frames: make(chan *bytes.Buffer, depth),
+		free:   make(chan *bytes.Buffer, depth+1),
+		done:   make(chan struct{}),
+	}
+	go q.drain(out)
+	return q
+}
+
+func (q *FrameQueue) drain(out io.Writer) {
+	defer close(q.done)
+	for frame := range q.frames {
+		if q.failed() == nil {
+			if _, err := out.Write(frame.Bytes()); err != nil {
+				q.mu.Lock()
+				q.err = err
+				q.mu.Unlock()
+			}
+		}
+		select {
+		case q.free <- frame:
+		default:
+		}
+	}
+}
+
+func (q *FrameQueue) failed() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.err
+}
+
+// Write queues the frame made by write, or drops it when the queue is full.
+// It returns the error of an earlier frame that failed to be written.
+func (q *FrameQueue) Write(write func(io.Writer) error) error {
+	if err := q.failed(); err != nil {
+		return err
+	}
+	if len(q.frames) == cap(q.frames) {
+		if q.Dropped == 0 {
+			slog.Warn("output is falling behind, dropping frames")
+		}
+		q.Dropped++
+		return nil
+	}
+
+	var frame *bytes.Buffer
+	select {
+	case frame = <-q.free:
+		frame.Reset()
+	default:
+		frame = new(bytes.Buffer)
+	}
+	if err := write(frame); err != nil {
+		return err
+	}
+	q.frames <- frame
+	return nil
+}
+
+// Close waits for the queued frames to be written
+func (q *FrameQueue) Close() error {
+	close(q.frames)
+	<-q.done
+	if q.Dropped > 0 {
+		slog.Info("output frames dropped", "count", q.Dropped)
+	}
+	return q.failed()
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// gatedWriter blocks every Write until the gate is closed
+type gatedWriter struct {
+	gate chan struct{}
+	buf  bytes.Buffer
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	return w.buf.Write(p)
+}
+
+func queueByte(q *FrameQueue, b byte) error {
+	return q.Write(func(w io.Writer) error {
+		_, err := w.Write([]byte{b})
+		return err
+	})
+}
+
+func TestFrameQueueDropsBehindStuckReader(t *testing.T) {
+	w := &gatedWriter{gate: make(chan struct{})}
+	q := NewFrameQueue(w, 2)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 50 {
+			if err := queueByte(q, byte(i)); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Write blocked on a reader that does not read")
+	}
+
+	close(w.gate)
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got := w.buf.Bytes()
+	if len(got)+q.Dropped != 50 || len(got) > 3 || got[0] != 0 {
+		t.Fatalf("wrote %v and dropped %d of 50 frames", got, q.Dropped)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i] <= got[i-1] {
+			t.Fatalf("frames written out of order: %v", got)
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestFrameQueueReportsWriteError(t *testing.T) {
+	q := NewFrameQueue(failingWriter{}, 2)
+	if err := queueByte(q, 0); err != nil {
+		t.Fatalf("the first frame failed before it was written: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for queueByte(q, 1) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("the write error never came back")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := q.Close(); err == nil {
+		t.Error("Close lost the write error")
+	}
+}
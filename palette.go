@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Colors for every cell state, packed by rgba
+type Palette [MAX_STATE + 1]uint32
+
+// Built in color sets, the window and pixel output can differ
+var palettePresets = []struct {
+	name   string
+	window Palette
+	pixel  Palette
+	// Cells the last generation changed with -activity
+	activity uint32
+}{
+	{
+		name: "default",
+		// DEAD+3 blends into the white background
+		window: Palette{
+			EMPTY:    rgba(0xFF, 0xFF, 0xFF),
+			BLUE:     rgba(0x00, 0x99, 0xFF),
+			ORANGE:   rgba(0xFF, 0x99, 0x00),
+			DEAD:     rgba(0x66, 0x66, 0x66),
+			DEAD + 1: rgba(0x7F, 0x7F, 0x7F),
+			DEAD + 2: rgba(0x99, 0x99, 0x99),
+			DEAD + 3: rgba(0xFF, 0xFF, 0xFF),
+		},
+		pixel: Palette{
+			EMPTY:    rgba(255, 255, 255),
+			BLUE:     rgba(0, 0, 255),
+			ORANGE:   rgba(255, 128, 0),
+			DEAD:     rgba(0, 0, 0),
+			DEAD + 1: rgba(136, 136, 136),
+			DEAD + 2: rgba(160, 160, 160),
+			DEAD + 3: rgba(238, 238, 238),
+		},
+		activity: rgba(0xFF, 0x00, 0xCC),
+	},
+	{
+		name:     "high-contrast",
+		window:   highContrastPalette,
+		pixel:    highContrastPalette,
+		activity: rgba(0xFF, 0x00, 0xFF),
+	},
+	{
+		// Blue and vermillion from the Okabe-Ito set
+		name:     "colorblind",
+		window:   colorblindPalette,
+		pixel:    colorblindPalette,
+		activity: rgba(0xCC, 0x79, 0xA7),
+	},
+}
+
+var (
+	highContrastPalette = Palette{
+		EMPTY:    rgba(0x00, 0x00, 0x00),
+		BLUE:     rgba(0x00, 0xFF, 0xFF),
+		ORANGE:   rgba(0xFF, 0xFF, 0x00),
+		DEAD:     rgba(0x99, 0x99, 0x99),
+		DEAD + 1: rgba(0x66, 0x66, 0x66),
+		DEAD + 2: rgba(0x33, 0x33, 0x33),
+		DEAD + 3: rgba(0x00, 0x00, 0x00),
+	}
+	colorblindPalette = Palette{
+		EMPTY:    rgba(0xFF, 0xFF, 0xFF),
+		BLUE:     rgba(0x00, 0x72, 0xB2),
+		ORANGE:   rgba(0xD5, 0x5E, 0x00),
+		DEAD:     rgba(0x33, 0x33, 0x33),
+		DEAD + 1: rgba(0x77, 0x77, 0x77),
+		DEAD + 2: rgba(0xBB, 0xBB, 0xBB),
+		DEAD + 3: rgba(0xEE, 0xEE, 0xEE),
+	}
+)
+
+var (
+	// Shown in the SDL window
+	windowPalette = palettePresets[0].window
+	// Written by the DensePixels protocol
+	pixelPalette = palettePresets[0].pixel
+	// Index of the preset in use
+	palettePreset = 0
+	// Cells the last generation changed with -activity, in the window and pixel output
+	activityColor = palettePresets[0].activity
+)
+
+// usePalettePreset switches both palettes to a preset, live
+func usePalettePreset(i int) {
+	palettePreset = i
+	windowPalette = palettePresets[i].window
+	pixelPalette = palettePresets[i].pixel
+	activityColor = palettePresets[i].activity
+	if *activityColorFlag != "" {
+		activityColor, _ = parseRGB(*activityColorFlag) // checked by configureOutput
+	}
+	if *decayFadeIn {
+		reverseDecay(&windowPalette)
+		reverseDecay(&pixelPalette)
+	}
+}
+
+// parseRGB reads a color written as RRGGBB in hex, with or without a leading #
+func parseRGB(text string) (uint32, error) {
+	hex := strings.TrimPrefix(text, "#")
+	c, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return 0, fmt.Errorf("bad color %q, expected RRGGBB in hex", text)
+	}
+	return rgba(uint8(c>>16), uint8(c>>8), uint8(c)), nil
+}
+
+func findPalettePreset(name string) (int, error) {
+	for i, preset := range palettePresets {
+		if preset.name == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown palette preset %q", name)
+}
+
+// Colors for live cells in ColorAge, from newborn to old
+var ageGradient = gradient(rgba(0xFF, 0xEE, 0x00), rgba(0x99, 0x00, 0x33), AGE_STEPS)
+
+// Color of changed cells in ColorDiff
+var diffColor = rgba(0xFF, 0x00, 0xCC)
+
+// Colors for 0 to 8 live neighbors in ColorNeighbors
+var neighborGradient = gradient(rgba(0x11, 0x11, 0x33), rgba(0xFF, 0xFF, 0x66), 9)
+
+// reverseDecay flips the grey ramp of the decay states, so they go from
+// light to dark instead. Only the colors change, not the states.
+func reverseDecay(palette *Palette) {
+	for i, j := DEAD, MAX_STATE; i < j; i, j = i+1, j-1 {
+		palette[i], palette[j] = palette[j], palette[i]
+	}
+}
+
+func rgba(r, g, b uint8) uint32 {
+	return uint32(b) | uint32(g)<<8 | uint32(r)<<16
+}
+
+func setDrawColor(renderer *sdl.Renderer, color uint32) {
+	renderer.SetDrawColor(uint8(color>>16), uint8(color>>8), uint8(color), 0xFF)
+}
+
+// gradient blends from one color to another in the given number of steps
+func gradient(from, to uint32, steps int) []uint32 {
+	colors := make([]uint32, steps)
+	for i := range colors {
+		var color uint32
+		for shift := 0; shift <= 16; shift += 8 {
+			a := int(from >> shift & 0xFF)
+			b := int(to >> shift & 0xFF)
+			color |= uint32(a+(b-a)*i/max(1, steps-1)) << shift
+		}
+		colors[i] = color
+	}
+	return colors
+}
+
+// colors returns the color table indexed by colorIndex, starting with palette
+func (g *Game) colors(palette *Palette) []uint32 {
+	colors := g.modeColors(palette)
+	if *activity {
+		colors = append(colors, activityColor)
+	}
+	return colors
+}
+
+// modeColors is the color table of the color mode, without the activity color
+func (g *Game) modeColors(palette *Palette) []uint32 {
+	switch colorMode {
+	case ColorAge:
+		return append(palette[:], ageGradient...)
+	case ColorDiff:
+		return append(palette[:], diffColor)
+	case ColorNeighbors:
+		return append(palette[:], neighborGradient...)
+	case ColorAux:
+		return append(palette[:], auxGradient...)
+	}
+	if g.elderAge > 0 {
+		return append(palette[:], elderShade(palette[BLUE]), elderShade(palette[ORANGE]))
+	}
+	return palette[:]
+}
+
+// modeColorCount is the length of the table modeColors returns, where the
+// activity color goes
+func (g *Game) modeColorCount() int {
+	switch {
+	case colorMode == ColorAge:
+		return MAX_STATE + 1 + AGE_STEPS
+	case colorMode == ColorDiff:
+		return MAX_STATE + 2
+	case colorMode == ColorNeighbors:
+		return MAX_STATE + 10
+	case colorMode == ColorAux:
+		return MAX_STATE + 1 + AUX_STEPS
+	case g.elderAge > 0:
+		return MAX_STATE + 3
+	}
+	return MAX_STATE + 1
+}
+
+// elderShade is a color halfway from c to white, for elder cells
+func elderShade(c uint32) uint32 {
+	return gradient(c, rgba(0xFF, 0xFF, 0xFF), 3)[1]
+}
+
+// colorIndex picks the entry of the color table used for a cell
+func (g *Game) colorIndex(x, y int) int {
+	if colorMode == ColorNeighbors {
+		blue_count, orange_count := g.CountNeighbors(x, y)
+		return MAX_STATE + 1 + blue_count + orange_count
+	}
+	if colorMode == ColorAux {
+		return MAX_STATE + 1 + auxStep(g.aux[x][y])
+	}
+	state := g.grid[x][y]
+	// Between Swap and the next Update nextGrid still holds the previous generation
+	if *activity && g.changed > 0 && g.nextGrid[x][y] != state {
+		return g.modeColorCount()
+	}
+	if colorMode == ColorAge && (state == BLUE || state == ORANGE) {
+		return MAX_STATE + 1 + ageStep(g.age[x][y])
+	}
+	if colorMode == ColorDiff {
+		if prev := g.history.Back(0); prev != nil && prev[x][y] != state {
+			return MAX_STATE + 1
+		}
+	}
+	if g.elderAge > 0 && colorMode == ColorState && (state == BLUE || state == ORANGE) && int(g.age[x][y]) >= g.elderAge {
+		return MAX_STATE + 1 + int(state-BLUE)
+	}
+	return int(state)
+}
+
+// fillMarginRow writes row y of the grid surrounded by m cells wrapped
+// around from the opposite edges, so the image tiles seamlessly
+func (g *Game) fillMarginRow(row []byte, y, m int, colors []uint32) {
+	for vx := range g.width + 2*m {
+		x, cy, _ := g.resolve(vx-m, y-m)
+		binary.LittleEndian.PutUint32(row[vx*4:], colors[g.colorIndex(x, cy)])
+	}
+}
+
+// widenRow writes every byte of narrow into wide as a little endian uint16
+// scaled to the full range
+func widenRow(wide, narrow []byte) {
+	for i, b := range narrow {
+		binary.LittleEndian.PutUint16(wide[2*i:], uint16(b)*257)
+	}
+}
+
+// fillPixelRow writes row y of the grid into row as little endian colors, 4 bytes per cell
+func (g *Game) fillPixelRow(row []byte, y int, colors []uint32) {
+	for x := range g.width {
+		binary.LittleEndian.PutUint32(row[x*4:], colors[g.colorIndex(x, y)])
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestElderShade(t *testing.T) {
+	// A blue and an orange block, both still lifes
+	g := makeGame(8, 4)
+	g.elderAge = 2
+	g.EnableAge()
+	for _, cell := range [][2]int{{1, 1}, {1, 2}, {2, 1}, {2, 2}} {
+		g.grid[cell[0]][cell[1]] = BLUE
+		g.grid[cell[0]+4][cell[1]] = ORANGE
+	}
+	g.countPopulation()
+
+	colors := g.colors(&pixelPalette)
+	if len(colors) != MAX_STATE+3 {
+		t.Fatalf("%d colors, want the palette and two elder shades", len(colors))
+	}
+	for generation := 1; generation <= 3; generation++ {
+		g.Update()
+		g.Swap()
+		blue, orange := BLUE, ORANGE
+		if generation >= g.elderAge {
+			blue, orange = MAX_STATE+1, MAX_STATE+2
+		}
+		if i := g.colorIndex(1, 1); i != blue {
+			t.Errorf("generation %d: blue block drawn with color %d, want %d", generation, i, blue)
+		}
+		if i := g.colorIndex(5, 1); i != orange {
+			t.Errorf("generation %d: orange block drawn with color %d, want %d", generation, i, orange)
+		}
+	}
+	if colors[MAX_STATE+1] == colors[BLUE] || colors[MAX_STATE+2] == colors[ORANGE] {
+		t.Error("elder cells look like the others")
+	}
+
+	// A cell that changes state starts over at age 0: the emptied corner
+	// is born again and the rest of the block, left with 2 neighbors, dies
+	g.Set(2, 2, EMPTY)
+	g.Update()
+	g.Swap()
+	for _, cell := range [][2]int{{1, 1}, {1, 2}, {2, 1}, {2, 2}} {
+		if age := g.age[cell[0]][cell[1]]; age != 0 {
+			t.Errorf("cell %d,%d became %s at age %d, want 0", cell[0], cell[1], stateNames[g.grid[cell[0]][cell[1]]], age)
+		}
+	}
+	if i := g.colorIndex(2, 2); g.grid[2][2] != BLUE || i != BLUE {
+		t.Errorf("reborn cell is %s drawn with color %d, want a young blue one", stateNames[g.grid[2][2]], i)
+	}
+	if i := g.colorIndex(5, 1); i != MAX_STATE+2 {
+		t.Errorf("untouched orange block drawn with color %d, want %d", i, MAX_STATE+2)
+	}
+}
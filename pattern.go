@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Cell is one non-empty cell of a pattern
+type Cell struct {
+	X, Y  int
+	State uint8
+}
+
+// Pattern is a board fragment loaded from an RLE file, a preset or a soup
+type Pattern struct {
+	Width, Height int
+	Cells         []Cell
+	// Rule named in the header, empty if there was none
+	Rule string
+	// Generation from a #CXRLE line, 0 if there was none
+	Generation int
+}
+
+// Small patterns selectable with -preset
+var presets = map[string]string{
+	"glider":  "x = 3, y = 3\nbo$2bo$3o!",
+	"lwss":    "x = 5, y = 4\nbo2bo$o4b$o3bo$4o!",
+	"blinker": "x = 3, y = 1\n3o!",
+	"toad":    "x = 4, y = 2\nb3o$3o!",
+	"pulsar": "x = 13, y = 13\n2b3o3b3o2b2$o4bobo4bo$o4bobo4bo$o4bobo4bo$2b3o3b3o2b2$2b3o3b3o2b$o4bobo4bo$" +
+		"o4bobo4bo$o4bobo4bo2$2b3o3b3o!",
+	"gosper": "x = 36, y = 9\n24bo$22bobo$12b2o6b2o12b2o$11bo3bo4b2o12b2o$2o8bo5bo3b2o$2o8bo3bob2o4bobo$" +
+		"10bo5bo7bo$11bo3bo$12b2o!",
+}
+
+func LoadPreset(name string) (*Pattern, error) {
+	rle, ok := presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q", name)
+	}
+	return ParseRLE(strings.NewReader(rle))
+}
+
+func LoadRLE(path string) (*Pattern, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	pattern, err := ParseRLE(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return pattern, nil
+}
+
+// ParseRLE reads a pattern in run length encoding. Two state files use b for
+// EMPTY and o for BLUE, multi state files use . for EMPTY and A, B, C... for
+// state 1, 2, 3...
+func ParseRLE(r io.Reader) (*Pattern, error) {
+	pattern := &Pattern{}
+	scanner := bufio.NewScanner(r)
+	headerSeen := false
+	x, y, run := 0, 0, 0
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#CXRLE") {
+			if err := pattern.parseCXRLE(line); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if !headerSeen {
+			headerSeen = true
+			if strings.HasPrefix(line, "x") {
+				if err := pattern.parseHeader(line); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		for _, c := range line {
+			switch {
+			case unicode.IsDigit(c):
+				run = run*10 + int(c-'0')
+				continue
+			case unicode.IsSpace(c):
+				continue
+			}
+
+			count := max(run, 1)
+			run = 0
+			switch {
+			case c == 'b' || c == '.':
+				x += count
+			case c == 'o' || (c >= 'A' && c <= 'X'):
+				state := uint8(BLUE)
+				if c != 'o' {
+					state = uint8(c-'A') + 1
+				}
+				if state > MAX_STATE {
+					return nil, fmt.Errorf("cell state %c is out of range", c)
+				}
+				for range count {
+					pattern.Cells = append(pattern.Cells, Cell{X: x, Y: y, State: state})
+					x++
+				}
+			case c == '$':
+				x = 0
+				y += count
+			case c == '!':
+				return pattern.fit(), nil
+			default:
+				return nil, fmt.Errorf("unexpected %q in pattern", c)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return pattern.fit(), nil
+}
+
+// parseHeader reads the "x = m, y = n, rule = B3/S23" line, the rule is optional
+func (p *Pattern) parseHeader(line string) error {
+	// Drop Golly's bounded grid suffix like :T100,100 before its comma
+	// splits the line
+	line, _, _ = strings.Cut(line, ":")
+	for _, field := range strings.Split(line, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return fmt.Errorf("bad header field %q", field)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch key {
+		case "x":
+			p.Width, err = strconv.Atoi(value)
+		case "y":
+			p.Height, err = strconv.Atoi(value)
+		case "rule":
+			p.Rule = value
+		}
+		if err != nil {
+			return fmt.Errorf("bad header field %q", field)
+		}
+	}
+	return nil
+}
+
+// parseCXRLE reads the generation from a "#CXRLE Pos=0,0 Gen=42" line
+func (p *Pattern) parseCXRLE(line string) error {
+	for _, field := range strings.Fields(line)[1:] {
+		key, value, _ := strings.Cut(field, "=")
+		if key != "Gen" {
+			continue
+		}
+		gen, err := strconv.Atoi(value)
+		if err != nil || gen < 0 {
+			return fmt.Errorf("bad generation %q", value)
+		}
+		p.Generation = gen
+	}
+	return nil
+}
+
+// fit grows the size to cover every cell, headers are not always accurate
+func (p *Pattern) fit() *Pattern {
+	for _, cell := range p.Cells {
+		p.Width = max(p.Width, cell.X+1)
+		p.Height = max(p.Height, cell.Y+1)
+	}
+	return p
+}
+
+// Game turns the pattern into a board of exactly its size, for Stamp
+func (p *Pattern) Game() *Game {
+	g := makeGame(p.Width, p.Height)
+	for _, cell := range p.Cells {
+		g.grid[cell.X][cell.Y] = cell.State
+	}
+	g.countPopulation()
+	return g
+}
+
+// Orient returns the pattern turned clockwise by r90, r180 or r270, or
+// mirrored left to right by flipx or top to bottom by flipy
+func (p *Pattern) Orient(orientation string) (*Pattern, error) {
+	w, h := p.Width, p.Height
+	var transform func(x, y int) (int, int)
+	switch orientation {
+	case "", "r0":
+		return p, nil
+	case "r90":
+		transform = func(x, y int) (int, int) { return p.Height - 1 - y, x }
+		w, h = h, w
+	case "r180":
+		transform = func(x, y int) (int, int) { return p.Width - 1 - x, p.Height - 1 - y }
+	case "r270":
+		transform = func(x, y int) (int, int) { return y, p.Width - 1 - x }
+		w, h = h, w
+	case "flipx":
+		transform = func(x, y int) (int, int) { return p.Width - 1 - x, y }
+	case "flipy":
+		transform = func(x, y int) (int, int) { return x, p.Height - 1 - y }
+	default:
+		return nil, fmt.Errorf("unknown orientation %q, expected r90, r180, r270, flipx or flipy", orientation)
+	}
+
+	oriented := &Pattern{Width: w, Height: h, Cells: make([]Cell, len(p.Cells)), Rule: p.Rule}
+	for i, cell := range p.Cells {
+		oriented.Cells[i].X, oriented.Cells[i].Y = transform(cell.X, cell.Y)
+		oriented.Cells[i].State = cell.State
+	}
+	return oriented, nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// cellsOf returns the cells of p sorted, so patterns compare by content
+func cellsOf(p *Pattern) []Cell {
+	cells := slices.Clone(p.Cells)
+	slices.SortFunc(cells, func(a, b Cell) int {
+		if a.X != b.X {
+			return a.X - b.X
+		}
+		return a.Y - b.Y
+	})
+	return cells
+}
+
+func TestOrientRoundTrip(t *testing.T) {
+	for _, name := range []string{"gosper", "lwss", "glider"} {
+		p, err := LoadPreset(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, turns := range [][]string{
+			{"r90", "r270"},
+			{"r270", "r90"},
+			{"r90", "r90", "r90", "r90"},
+			{"r180", "r180"},
+			{"flipx", "flipx"},
+			{"flipy", "flipy"},
+			{"flipx", "flipy", "r180"},
+		} {
+			q := p
+			for _, turn := range turns {
+				if q, err = q.Orient(turn); err != nil {
+					t.Fatal(err)
+				}
+				for _, cell := range q.Cells {
+					if cell.X < 0 || cell.X >= q.Width || cell.Y < 0 || cell.Y >= q.Height {
+						t.Fatalf("%s %v: cell %d,%d outside %dx%d", name, turns, cell.X, cell.Y, q.Width, q.Height)
+					}
+				}
+			}
+			if q.Width != p.Width || q.Height != p.Height || !slices.Equal(cellsOf(q), cellsOf(p)) {
+				t.Errorf("%s %v does not give the pattern back", name, turns)
+			}
+		}
+	}
+}
+
+func TestOrientR90(t *testing.T) {
+	// Two cells in a 3x2 pattern, turned clockwise into 2x3
+	p := &Pattern{Width: 3, Height: 2, Cells: []Cell{{0, 0, BLUE}, {2, 1, ORANGE}}}
+	q, err := p.Orient("r90")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Cell{{0, 2, ORANGE}, {1, 0, BLUE}}
+	if q.Width != 2 || q.Height != 3 || !slices.Equal(cellsOf(q), want) {
+		t.Errorf("got %dx%d %v, want 2x3 %v", q.Width, q.Height, cellsOf(q), want)
+	}
+
+	// A turned non-square pattern swaps its sides and its board holds
+	// every turned cell
+	gosper, err := LoadPreset("gosper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, turn := range []struct {
+		orientation string
+		transform   func(x, y int) (int, int)
+	}{
+		{"r90", func(x, y int) (int, int) { return gosper.Height - 1 - y, x }},
+		{"r270", func(x, y int) (int, int) { return y, gosper.Width - 1 - x }},
+	} {
+		turned, err := gosper.Orient(turn.orientation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if turned.Width != gosper.Height || turned.Height != gosper.Width {
+			t.Errorf("%s: gosper turned to %dx%d, want %dx%d", turn.orientation, turned.Width, turned.Height, gosper.Height, gosper.Width)
+		}
+		var want []Cell
+		for _, cell := range gosper.Cells {
+			x, y := turn.transform(cell.X, cell.Y)
+			want = append(want, Cell{x, y, cell.State})
+		}
+		p := &Pattern{Cells: want}
+		if !slices.Equal(cellsOf(turned), cellsOf(p)) {
+			t.Errorf("%s: gosper turned to %v, want %v", turn.orientation, cellsOf(turned), cellsOf(p))
+		}
+		board := turned.Game()
+		for _, cell := range want {
+			if board.grid[cell.X][cell.Y] != cell.State {
+				t.Errorf("%s: cell %d,%d is %d on the board, want %d", turn.orientation, cell.X, cell.Y, board.grid[cell.X][cell.Y], cell.State)
+			}
+		}
+	}
+}
+
+func TestOrientedGliderGlides(t *testing.T) {
+	glider, err := LoadPreset("glider")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Under Conway's rule the preset glider moves one cell right and down
+	// every 4 generations, turned it moves the turned way
+	for _, turn := range []struct {
+		orientation string
+		dx, dy      int
+	}{
+		{"r0", 1, 1},
+		{"r90", -1, 1},
+		{"r180", -1, -1},
+		{"r270", 1, -1},
+	} {
+		turned, err := glider.Orient(turn.orientation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := newEmptyGame(20, 20, DoubleBuffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.rule = mustParseRule("B3/S23")
+		g.SetClassic(true)
+		g.Stamp(turned.Game(), 8, 8, Overwrite)
+		for range 4 {
+			g.Update()
+			g.Swap()
+		}
+		want := makeGame(20, 20)
+		want.Stamp(turned.Game(), 8+turn.dx, 8+turn.dy, Overwrite)
+		if !g.Equal(want) {
+			t.Errorf("%s: the glider did not move by %d,%d in 4 generations", turn.orientation, turn.dx, turn.dy)
+		}
+	}
+}
+
+func TestParseHeaderGollySuffix(t *testing.T) {
+	for _, header := range []string{
+		"x = 3, y = 3, rule = B3/S23:T100,100",
+		"x = 3, y = 3, rule = B3/S23:P20,30",
+		"x = 3, y = 3, rule = B3/S23",
+		"x=3,y=3,rule=B3/S23:T100,100",
+	} {
+		p, err := ParseRLE(strings.NewReader(header + "\nbo$2bo$3o!"))
+		if err != nil {
+			t.Errorf("%q: %v", header, err)
+			continue
+		}
+		if p.Width != 3 || p.Height != 3 || p.Rule != "B3/S23" || len(p.Cells) != 5 {
+			t.Errorf("%q read as %dx%d, rule %q, %d cells", header, p.Width, p.Height, p.Rule, len(p.Cells))
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Shades a -persistence trail fades through
+const TRAIL_STEPS = 32
+
+// Trails keeps a fading mark in the window where cells were live, for
+// -persistence. It runs on the clock, not on generations, so trails go on
+// fading while the game is paused or slow. Only the window shows them.
+type Trails struct {
+	duration time.Duration
+	start    time.Time
+	// Milliseconds from start to the last frame the cell was live, plus
+	// one so 0 means never
+	seen [][]uint32
+	now  uint32
+	// Reused by draw, one group per shade
+	rects [TRAIL_STEPS][]sdl.Rect
+}
+
+func NewTrails(duration time.Duration, width, height int) *Trails {
+	seen := make([][]uint32, width)
+	for x := range seen {
+		seen[x] = make([]uint32, height)
+	}
+	return &Trails{duration: duration, start: time.Now(), seen: seen}
+}
+
+// Update marks the cells that are live now, once per frame
+func (t *Trails) Update(g *Game, now time.Time) {
+	t.now = uint32(now.Sub(t.start).Milliseconds()) + 1
+	for x := range g.width {
+		for y := range g.height {
+			if state := g.grid[x][y]; state == BLUE || state == ORANGE {
+				t.seen[x][y] = t.now
+			}
+		}
+	}
+}
+
+// draw covers every cell that is not live but was within the duration with
+// a shade fading from the first DEAD color to the EMPTY one. Live cells
+// keep their own color.
+func (t *Trails) draw(g *Game, renderer *sdl.Renderer) {
+	for i := range t.rects {
+		t.rects[i] = t.rects[i][:0]
+	}
+	duration := uint32(max(1, t.duration.Milliseconds()))
+	z := int32(*zoom)
+	for x := range g.width {
+		for y := range g.height {
+			seen := t.seen[x][y]
+			if seen == 0 || t.now-seen >= duration {
+				continue
+			}
+			if state := g.grid[x][y]; state == BLUE || state == ORANGE {
+				continue
+			}
+			step := int((t.now - seen) * TRAIL_STEPS / duration)
+			t.rects[step] = append(t.rects[step], sdl.Rect{X: int32(x) * z, Y: int32(y) * z, W: z, H: z})
+		}
+	}
+	for step, rects := range t.rects {
+		if len(rects) == 0 {
+			continue
+		}
+		setDrawColor(renderer, mixColor(windowPalette[DEAD], windowPalette[EMPTY], float64(step)/TRAIL_STEPS))
+		inBatches(rects, renderer.FillRects)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Pin keeps cells in a fixed state whatever their neighbors are. They still
+// count as neighbors, so a pinned BLUE cell acts as a constant source.
+func (g *Game) Pin(cells []Cell) error {
+	if g.pinned == nil {
+		g.pinned = make([][]uint8, g.width)
+		for x := range g.pinned {
+			g.pinned[x] = make([]uint8, g.height)
+		}
+	}
+
+	for _, cell := range cells {
+		x, y, ok := g.resolvePlaced(cell.X, cell.Y)
+		if !ok || cell.State == EMPTY {
+			return fmt.Errorf("cannot pin %d,%d", cell.X, cell.Y)
+		}
+		g.pinned[x][y] = cell.State
+		g.grid[x][y] = cell.State
+	}
+	g.countPopulation()
+	return nil
+}
+
+// parsePins reads BLUE cells as "x,y" pairs separated by spaces or semicolons
+func parsePins(text string) ([]Cell, error) {
+	var cells []Cell
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool {
+		return r == ';' || r == ' ' || r == '\t' || r == '\n'
+	}) {
+		var cell Cell
+		if _, err := fmt.Sscanf(field, "%d,%d", &cell.X, &cell.Y); err != nil {
+			return nil, fmt.Errorf("bad pinned cell %q, expected x,y", field)
+		}
+		cell.State = BLUE
+		cells = append(cells, cell)
+	}
+	return cells, nil
+}
+
+// loadPins reads a file of "x,y" lines, # starts a comment
+func loadPins(path string) ([]Cell, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var text strings.Builder
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		text.WriteString(line + ";")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return parsePins(text.String())
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// PNGFrames writes every generation as a numbered PNG into a directory, for
+// assembling with ffmpeg or ImageMagick
+type PNGFrames struct {
+	dir    string
+	digits int
+	next   int
+}
+
+// NewPNGFrames creates dir if needed. Frames are numbered from start, zero
+// padded to digits.
+func NewPNGFrames(dir string, digits, start int) (*PNGFrames, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &PNGFrames{dir: dir, digits: digits, next: start}, nil
+}
+
+// checkPNGDir refuses a directory that already holds files unless force is
+// set, so an earlier recording is not overwritten by accident
+func checkPNGDir(dir string, force bool) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 && !force {
+		return fmt.Errorf("-png-dir %s is not empty, use -force to write into it anyway", dir)
+	}
+	return nil
+}
+
+// Save is a GenerationFunc
+func (p *PNGFrames) Save(g *Game, gen int) error {
+	path := filepath.Join(p.dir, fmt.Sprintf("%0*d.png", p.digits, p.next))
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("png frame: %w", err)
+	}
+	if err := png.Encode(f, g.Image()); err != nil {
+		f.Close()
+		return fmt.Errorf("png frame: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("png frame: %w", err)
+	}
+	p.next++
+	return nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type RenderMode int
+
+const (
+	RenderPoints RenderMode = iota
+	RenderTexture
+	RenderSquares // a FillRect of -zoom pixels per cell
+	RenderCircles // a filled circle as wide as -zoom per cell
+)
+
+var renderMode = RenderPoints
+
+// ColorMode decides what the color of a cell shows
+type ColorMode int
+
+const (
+	ColorState     ColorMode = iota
+	ColorAge                 // live cells shaded by how long they survived
+	ColorDiff                // cells changed by the last generation highlighted
+	ColorNeighbors           // every cell by its number of live neighbors, for debugging
+	ColorAux                 // every cell by its aux value
+)
+
+var colorMode = ColorState
+
+func parseRenderMode(name string) (RenderMode, error) {
+	switch name {
+	case "points":
+		return RenderPoints, nil
+	case "texture":
+		return RenderTexture, nil
+	case "squares":
+		return RenderSquares, nil
+	case "circles":
+		return RenderCircles, nil
+	}
+	return RenderPoints, fmt.Errorf("unknown render mode %q, expected points, texture, squares or circles", name)
+}
+
+func parseColorMode(name string) (ColorMode, error) {
+	switch name {
+	case "state":
+		return ColorState, nil
+	case "age":
+		return ColorAge, nil
+	case "diff":
+		return ColorDiff, nil
+	case "neighbors":
+		return ColorNeighbors, nil
+	case "aux":
+		return ColorAux, nil
+	}
+	return ColorState, fmt.Errorf("unknown color mode %q, expected state, age, diff, neighbors or aux", name)
+}
+
+// drawBoard draws the game the way renderMode asks
+// Points only cover a cell at -zoom 1, above that they are drawn as squares.
+func (g *Game) drawBoard(renderer *sdl.Renderer) {
+	if g.trails != nil {
+		defer g.trails.draw(g, renderer)
+	}
+	switch {
+	case g.blend > 0:
+		g.DrawBlend(renderer, g.blend)
+	case renderMode == RenderTexture:
+		g.DrawTexture(renderer)
+	case renderMode == RenderCircles:
+		g.DrawShapes(renderer, circleShape(*zoom))
+	case renderMode == RenderSquares || *zoom > 1:
+		g.DrawShapes(renderer, []sdl.Rect{{W: int32(*zoom), H: int32(*zoom)}})
+	default:
+		g.Draw(renderer)
+	}
+}
+
+// DrawShapes draws every cell not in the EMPTY color as shape, rectangles
+// relative to the top left corner of the cell's -zoom sized square
+func (g *Game) DrawShapes(renderer *sdl.Renderer, shape []sdl.Rect) {
+	colors := g.colors(&windowPalette)
+	if len(g.rects) != len(colors) {
+		g.rects = make([][]sdl.Rect, len(colors))
+	}
+	for i := range g.rects {
+		g.rects[i] = g.rects[i][:0]
+	}
+
+	z := int32(*zoom)
+	for x := range g.width {
+		for y := range g.height {
+			i := g.colorIndex(x, y)
+			if colors[i] == colors[EMPTY] {
+				continue
+			}
+			for _, r := range shape {
+				r.X += int32(x) * z
+				r.Y += int32(y) * z
+				g.rects[i] = append(g.rects[i], r)
+			}
+		}
+	}
+
+	for i, rects := range g.rects {
+		if len(rects) == 0 {
+			continue
+		}
+		setDrawColor(renderer, colors[i])
+		inBatches(rects, renderer.FillRects)
+	}
+}
+
+// inBatches hands items to draw at most -draw-batch at a time, some
+// backends fail on very large single calls
+func inBatches[T any](items []T, draw func([]T) error) {
+	n := *drawBatch
+	if n == 0 {
+		n = len(items)
+	}
+	for len(items) > n {
+		draw(items[:n])
+		items = items[n:]
+	}
+	if len(items) > 0 {
+		draw(items)
+	}
+}
+
+// circleShape is a filled circle of diameter size as one rectangle per pixel row
+func circleShape(size int) []sdl.Rect {
+	var shape []sdl.Rect
+	radius := float64(size) / 2
+	for row := range size {
+		dy := float64(row) + 0.5 - radius
+		half := math.Sqrt(radius*radius - dy*dy)
+		from := int32(math.Round(radius - half))
+		to := int32(math.Round(radius + half))
+		if to > from {
+			shape = append(shape, sdl.Rect{X: from, Y: int32(row), W: to - from, H: 1})
+		}
+	}
+	return shape
+}
+
+// drawInViewport draws the game with its top left corner at x, y of the window
+func (g *Game) drawInViewport(renderer *sdl.Renderer, x, y int32) {
+	renderer.SetViewport(&sdl.Rect{X: x, Y: y, W: int32(g.width * *zoom), H: int32(g.height * *zoom)})
+	g.drawBoard(renderer)
+	renderer.SetViewport(nil)
+}
+
+// releaseTexture frees the texture of DrawTexture, which makes a new one when next called
+func (g *Game) releaseTexture() {
+	if g.texture != nil {
+		g.texture.Destroy()
+		g.texture = nil
+	}
+}
+
+// DrawTexture renders the current state by uploading every pixel to a
+// streaming texture and copying it to the window in one call.
+// Faster than Draw once most of the board is not EMPTY.
+func (g *Game) DrawTexture(renderer *sdl.Renderer) {
+	if g.texture == nil {
+		texture, err := renderer.CreateTexture(sdl.PIXELFORMAT_ARGB8888, sdl.TEXTUREACCESS_STREAMING,
+			int32(g.width), int32(g.height))
+		if err != nil {
+			g.Draw(renderer)
+			return
+		}
+		// The palette leaves alpha at 0, so don't blend
+		texture.SetBlendMode(sdl.BLENDMODE_NONE)
+		g.texture = texture
+	}
+
+	// A texture lost to a resize is made again next frame, this one is
+	// drawn without it
+	pixels, pitch, err := g.texture.Lock(nil)
+	if err != nil {
+		g.releaseTexture()
+		g.Draw(renderer)
+		return
+	}
+	colors := g.colors(&windowPalette)
+	for y := range g.height {
+		g.fillPixelRow(pixels[y*pitch:], y, colors)
+	}
+	g.texture.Unlock()
+
+	renderer.Copy(g.texture, nil, &sdl.Rect{X: 0, Y: 0, W: int32(g.width * *zoom), H: int32(g.height * *zoom)})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestInBatches(t *testing.T) {
+	defer func(n int) { *drawBatch = n }(*drawBatch)
+	items := make([]int, 23)
+	for i := range items {
+		items[i] = i
+	}
+	for _, c := range []struct{ batch, calls int }{{0, 1}, {1, 23}, {7, 4}, {23, 1}, {100, 1}} {
+		*drawBatch = c.batch
+		var drawn []int
+		calls := 0
+		inBatches(items, func(batch []int) error {
+			if c.batch > 0 && len(batch) > c.batch {
+				t.Errorf("-draw-batch %d: drew %d at once", c.batch, len(batch))
+			}
+			drawn = append(drawn, batch...)
+			calls++
+			return nil
+		})
+		if !slices.Equal(drawn, items) || calls != c.calls {
+			t.Errorf("-draw-batch %d: drew %v in %d calls, want every item once in %d", c.batch, drawn, calls, c.calls)
+		}
+	}
+}
+
+// TestDrawBatchesSamePixels draws into a software renderer, so it needs no window
+func TestDrawBatchesSamePixels(t *testing.T) {
+	defer func(n int, mode RenderMode) { *drawBatch, renderMode = n, mode }(*drawBatch, renderMode)
+	g, err := newEmptyGame(64, 48, DoubleBuffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.fillSeeded(9, nil)
+	draw := func() []byte {
+		surface, err := sdl.CreateRGBSurface(0, int32(g.width), int32(g.height), 32, 0, 0, 0, 0)
+		if err != nil {
+			t.Skip(err)
+		}
+		defer surface.Free()
+		renderer, err := sdl.CreateSoftwareRenderer(surface)
+		if err != nil {
+			t.Skip(err)
+		}
+		defer renderer.Destroy()
+		g.drawBoard(renderer)
+		return bytes.Clone(surface.Pixels())
+	}
+	for _, mode := range []RenderMode{RenderPoints, RenderSquares} {
+		renderMode = mode
+		*drawBatch = 0
+		whole := draw()
+		*drawBatch = 7
+		if !bytes.Equal(draw(), whole) {
+			t.Errorf("render mode %d: drawing in batches changed the pixels", mode)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Replay is a recorded DenseCells stream. Every frame is width*height bytes
+// in row order without a header, so frame n starts at byte n*width*height
+// and any frame can be read without reading the ones before it.
+type Replay struct {
+	f             *os.File
+	width, height int
+	frames        int
+}
+
+// OpenReplay opens a recording of a width x height grid
+func OpenReplay(path string, width, height int) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	size := int64(width) * int64(height)
+	if info.Size()%size != 0 {
+		f.Close()
+		return nil, fmt.Errorf("%s is %d bytes, not a whole number of %dx%d frames", path, info.Size(), width, height)
+	}
+	return &Replay{f: f, width: width, height: height, frames: int(info.Size() / size)}, nil
+}
+
+// Frames returns the number of frames in the recording
+func (r *Replay) Frames() int {
+	return r.frames
+}
+
+// Frame reads frame n into a new game, numbered as generation n
+func (r *Replay) Frame(n int) (*Game, error) {
+	if n < 0 || n >= r.frames {
+		return nil, fmt.Errorf("frame %d is out of range, the recording has %d frames (0 to %d)", n, r.frames, r.frames-1)
+	}
+	g, err := newEmptyGame(r.width, r.height, gridBuffers())
+	if err != nil {
+		return nil, err
+	}
+	row := make([]byte, r.width)
+	offset := int64(n) * int64(r.width) * int64(r.height)
+	for y := range r.height {
+		if _, err := r.f.ReadAt(row, offset+int64(y*r.width)); err != nil {
+			return nil, err
+		}
+		for x, state := range row {
+			if state > MAX_STATE {
+				return nil, fmt.Errorf("frame %d has state %d at %d,%d, not a DenseCells recording", n, state, x, y)
+			}
+			g.grid[x][y] = state
+		}
+	}
+	g.generation = n
+	g.countPopulation()
+	return g, nil
+}
+
+func (r *Replay) Close() error {
+	return r.f.Close()
+}
+
+// runReplay writes frame seek of the recording at path to w in the selected
+// protocol, or as RLE when the protocol is off, and saves it with -svg
+func runReplay(w io.Writer, path string, seek int) error {
+	replay, err := OpenReplay(path, *width, *height)
+	if err != nil {
+		return err
+	}
+	defer replay.Close()
+
+	game, err := replay.Frame(seek)
+	if err != nil {
+		return err
+	}
+	if *svgPath != "" {
+		if err := game.SaveSVG(*svgPath); err != nil {
+			return err
+		}
+	}
+	if protocol == Off {
+		return game.WriteRLE(w)
+	}
+	return game.writeFrame(w)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Rule lists the live neighbor counts at which an EMPTY cell is born and a
+// live cell survives
+type Rule struct {
+	Birth    [9]bool
+	Survival [9]bool
+}
+
+// The rule golife has always used, new games start with it
+const DEFAULT_RULE = "B3/S345"
+
+var defaultRule = mustParseRule(DEFAULT_RULE)
+
+// ParseRule reads a rule like B3/S23, in either order and any case.
+// The older survival/birth form 23/3 is accepted too.
+func ParseRule(text string) (Rule, error) {
+	var rule Rule
+	parts := strings.Split(strings.ToUpper(strings.TrimSpace(text)), "/")
+	if len(parts) != 2 {
+		return rule, fmt.Errorf("bad rule %q, expected something like B3/S23", text)
+	}
+
+	// Without letters the survival counts come first
+	if !strings.ContainsAny(parts[0]+parts[1], "BS") {
+		parts[0], parts[1] = "S"+parts[0], "B"+parts[1]
+	}
+
+	seen := map[byte]bool{}
+	for _, part := range parts {
+		if part == "" || (part[0] != 'B' && part[0] != 'S') || seen[part[0]] {
+			return rule, fmt.Errorf("bad rule %q, expected something like B3/S23", text)
+		}
+		seen[part[0]] = true
+
+		counts := &rule.Birth
+		if part[0] == 'S' {
+			counts = &rule.Survival
+		}
+		for _, c := range part[1:] {
+			if c < '0' || c > '8' {
+				return rule, fmt.Errorf("bad neighbor count %q in rule %q", c, text)
+			}
+			counts[c-'0'] = true
+		}
+	}
+	return rule, nil
+}
+
+// String formats the rule as B3/S23, the form ParseRule reads back
+func (r Rule) String() string {
+	var b strings.Builder
+	b.WriteByte('B')
+	for n, born := range r.Birth {
+		if born {
+			b.WriteByte('0' + byte(n))
+		}
+	}
+	b.WriteString("/S")
+	for n, survives := range r.Survival {
+		if survives {
+			b.WriteByte('0' + byte(n))
+		}
+	}
+	return b.String()
+}
+
+// FormatRule writes birth and survival counts as B3/S23
+func FormatRule(birth, survival [9]bool) string {
+	return Rule{birth, survival}.String()
+}
+
+// Rule returns the neighbor counts at which the game gives birth and keeps
+// cells alive
+func (g *Game) Rule() (birth, survival [9]bool) {
+	return g.rule.Birth, g.rule.Survival
+}
+
+// SetRule changes the rule of the game, the next Update uses it
+func (g *Game) SetRule(birth, survival [9]bool) {
+	g.rule = Rule{birth, survival}
+}
+
+func mustParseRule(text string) Rule {
+	rule, err := ParseRule(text)
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+// startRule picks the rule for the run. A rule from the pattern file wins
+// unless -rule was given as well, then the flag wins with a warning when the
+// two disagree.
+func startRule(patternRule string) (Rule, error) {
+	rule, err := ParseRule(*ruleFlag)
+	if err != nil || patternRule == "" {
+		return rule, err
+	}
+
+	fileRule, err := ParseRule(patternRule)
+	if err != nil {
+		slog.Warn("ignoring the rule of the pattern", "err", err)
+		return rule, nil
+	}
+	if !flagSet("rule") {
+		return fileRule, nil
+	}
+	if fileRule != rule {
+		slog.Warn("pattern is for another rule, running the one from -rule", "pattern", patternRule, "rule", *ruleFlag)
+	}
+	return rule, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// WriteRuleTable writes the next state CellChange picks for every state and
+// every mix of blue and orange neighbors under the game's current settings.
+// Decaying cells ignore their neighbors, so they get a single row each.
+func (g *Game) WriteRuleTable(w io.Writer) error {
+	// A 3x3 plane whose center is surrounded by exactly the counted neighbors
+	probe := makeGame(3, 3)
+	probe.edge = Bounded
+	probe.neighborhood = g.neighborhood
+	probe.rule = g.rule
+	probe.enemyMargin = g.enemyMargin
+	probe.birthBias = g.birthBias
+	probe.classic = g.classic
+
+	var around [][2]int
+	probe.forEachNeighbor(1, 1, func(nx, ny int) {
+		around = append(around, [2]int{nx, ny})
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "state\tblue\torange\tnext")
+	for state := uint8(EMPTY); state <= MAX_STATE; state++ {
+		probe.grid[1][1] = state
+		if state >= DEAD {
+			fmt.Fprintf(tw, "%s\t*\t*\t%s\n", stateNames[state], stateNames[probe.CellChange(1, 1)])
+			continue
+		}
+		for blue := 0; blue <= len(around); blue++ {
+			for orange := 0; blue+orange <= len(around); orange++ {
+				for i, at := range around {
+					switch {
+					case i < blue:
+						probe.grid[at[0]][at[1]] = BLUE
+					case i < blue+orange:
+						probe.grid[at[0]][at[1]] = ORANGE
+					default:
+						probe.grid[at[0]][at[1]] = EMPTY
+					}
+				}
+				fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", stateNames[state], blue, orange, stateNames[probe.CellChange(1, 1)])
+			}
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,68 @@
+package main
+
+import "testing"
+
+func TestParseRule(t *testing.T) {
+	for text, want := range map[string]string{
+		"B3/S23":                "B3/S23",
+		"b3/s23":                "B3/S23",
+		"S23/B3":                "B3/S23",
+		"23/3":                  "B3/S23",
+		" B36/S23 ":             "B36/S23",
+		"B/S":                   "B/S",
+		"/":                     "B/S",
+		"S8/B0":                 "B0/S8",
+		"B012345678/S012345678": "B012345678/S012345678",
+	} {
+		r, err := ParseRule(text)
+		if err != nil {
+			t.Errorf("%q: %v", text, err)
+			continue
+		}
+		if got := r.String(); got != want {
+			t.Errorf("%q formatted as %q, want %q", text, got, want)
+		}
+		if back, err := ParseRule(r.String()); err != nil || back != r {
+			t.Errorf("%q does not read back: %v", r.String(), err)
+		}
+		if FormatRule(r.Birth, r.Survival) != r.String() {
+			t.Errorf("FormatRule and String disagree on %q", text)
+		}
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	for _, text := range []string{"", "B3", "B3/S9", "B3/B4", "X3/S2", "B3/S2/S1", "B3/Sa"} {
+		if r, err := ParseRule(text); err == nil {
+			t.Errorf("%q parsed as %v", text, r)
+		}
+	}
+}
+
+func TestSetRule(t *testing.T) {
+	g := makeGame(6, 5)
+	if birth, survival := g.Rule(); FormatRule(birth, survival) != DEFAULT_RULE {
+		t.Fatalf("a new game runs %s, want %s", FormatRule(birth, survival), DEFAULT_RULE)
+	}
+
+	// A beehive is still under Conway's rule, under the default one the
+	// cells with two neighbors die
+	beehive := [][2]int{{2, 1}, {3, 1}, {1, 2}, {4, 2}, {2, 3}, {3, 3}}
+	for _, cell := range beehive {
+		g.grid[cell[0]][cell[1]] = BLUE
+	}
+	g.countPopulation()
+	conway := mustParseRule("B3/S23")
+	g.SetRule(conway.Birth, conway.Survival)
+	g.Update()
+	g.Swap()
+	if g.Live() != len(beehive) {
+		t.Errorf("%d live cells after a generation of B3/S23, want the beehive's %d", g.Live(), len(beehive))
+	}
+	g.SetRule(defaultRule.Birth, defaultRule.Survival)
+	g.Update()
+	g.Swap()
+	if g.Live() == len(beehive) {
+		t.Errorf("the beehive survived %s", DEFAULT_RULE)
+	}
+}
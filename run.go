@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// ErrStop can be returned by a GenerationFunc to end Run without an error
+var ErrStop = errors.New("stop")
+
+// GenerationFunc is called by Run after each generation, before the next one
+// is computed. It runs on the goroutine of Run, so it may read and change the game.
+type GenerationFunc func(g *Game, gen int) error
+
+// OnGeneration registers fn to be called after every generation
+func (game *Game) OnGeneration(fn GenerationFunc) {
+	game.callbacks = append(game.callbacks, fn)
+}
+
+// handleSignal pauses or resumes the game on pauseSignal and advances it by
+// one generation, pausing it first, on stepSignal
+func (game *Game) handleSignal(sig os.Signal) {
+	switch sig {
+	case pauseSignal:
+		game.paused = !game.paused
+		game.blurPaused = false
+		slog.Info("pause toggled by signal", "paused", game.paused, "generation", game.Generation())
+	case stepSignal:
+		game.requestStep()
+		slog.Info("step requested by signal", "generation", game.Generation())
+	}
+}
+
+// requestStep pauses the game and advances it by one generation
+func (game *Game) requestStep() {
+	game.paused = true
+	game.edit(func(g *Game) { g.step = true })
+}
+
+// edit applies fn to the game. Under RunDecoupled the board belongs to the
+// simulating goroutine, so fn is sent there and applied before its next
+// generation. An edit is dropped with a warning when the simulation has
+// stopped taking them.
+func (game *Game) edit(fn func(g *Game)) {
+	if game.edits == nil {
+		fn(game)
+		return
+	}
+	select {
+	case game.edits <- fn:
+	default:
+		slog.Warn("the simulation is not taking changes, one was dropped")
+	}
+}
+
+// Run advances the game until ctx is cancelled, the window is closed or a
+// stop condition is met. Every generation is shown in the window when
+// renderer is set and written to out in the selected protocol. The first
+// one waits for the start delay. With -decouple it hands over to
+// RunDecoupled once the delay is over.
+func (game *Game) Run(ctx context.Context, out io.Writer, renderer *sdl.Renderer) error {
+	if game.startDelay > 0 {
+		if err := game.holdStart(ctx, renderer, game.startDelay); err != nil {
+			return err
+		}
+	}
+	if *decouple {
+		return game.RunDecoupled(ctx, out, renderer)
+	}
+	return game.runCoupled(ctx, out, renderer)
+}
+
+// runCoupled is Run computing, showing and writing every generation in turn
+// on the calling goroutine
+func (game *Game) runCoupled(ctx context.Context, out io.Writer, renderer *sdl.Renderer) error {
+	idleGenerations := 0
+	for !game.quit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		select {
+		case sig := <-game.control:
+			game.handleSignal(sig)
+		default:
+		}
+
+		if game.paused && !game.step {
+			if *visual {
+				game.visualize(renderer)
+			}
+			game.wait(10 * time.Millisecond)
+			continue
+		}
+		game.step = false
+
+		if game.limiter != nil {
+			game.limiter.Wait(game.wait)
+		}
+
+		if err := game.OutputAll(renderer, out); err != nil {
+			return fmt.Errorf("output: %w", err)
+		}
+
+		start := time.Now()
+		game.Update()
+		took := time.Since(start)
+		if game.latency != nil {
+			game.latency.Record(took)
+		}
+		if game.throttle != nil {
+			game.throttle.Wait(took, game.wait)
+		}
+
+		changed := game.SwapAndCount()
+		if *visual && game.smoothFrames > 0 {
+			game.drawSmooth(renderer)
+		}
+
+		if !*visual {
+			tickFPS() // visualize counts frames otherwise
+		}
+		for _, fn := range game.callbacks {
+			if err := fn(game, game.Generation()); err != nil {
+				if errors.Is(err, ErrStop) {
+					return nil
+				}
+				return err
+			}
+		}
+
+		// Slow down once nothing has changed for a while
+		if changed == 0 {
+			idleGenerations++
+		} else {
+			idleGenerations = 0
+		}
+		if *idleAfter > 0 && idleGenerations >= *idleAfter {
+			game.wait(*stepDelay)
+		}
+
+		if *stopOnEmpty && game.Live() == 0 {
+			slog.Info("board empty", "generation", game.Generation())
+			if !*visual {
+				return nil
+			}
+			// Keep showing the final board until the window is closed
+			game.paused = true
+		}
+	}
+	return nil
+}
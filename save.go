@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// Longest line WriteRLE writes, the limit the RLE format asks for
+const RLE_LINE_LENGTH = 70
+
+// WriteRLE writes the board as a multi-state RLE pattern that ParseRLE reads
+// back: . for EMPTY and A, B, ... for the other states. A #CXRLE line records
+// the generation.
+func (g *Game) WriteRLE(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "#CXRLE Gen=%d\n", g.generation)
+	fmt.Fprintf(bw, "x = %d, y = %d, rule = %s\n", g.width, g.height, g.rule)
+
+	line := 0
+	put := func(count int, tag byte) {
+		token := string(tag)
+		if count > 1 {
+			token = fmt.Sprint(count) + token
+		}
+		if line+len(token) > RLE_LINE_LENGTH {
+			bw.WriteByte('\n')
+			line = 0
+		}
+		bw.WriteString(token)
+		line += len(token)
+	}
+
+	emptyRows := 0
+	started := false
+	for y := range g.height {
+		// Trailing EMPTY cells of a row are left out
+		end := g.width
+		for end > 0 && g.grid[end-1][y] == EMPTY {
+			end--
+		}
+		if end == 0 {
+			emptyRows++
+			continue
+		}
+		// One $ ends the last row written, the rest skip empty ones. Before
+		// the first row only the empty rows are skipped.
+		switch {
+		case started:
+			put(emptyRows+1, '$')
+		case emptyRows > 0:
+			put(emptyRows, '$')
+		}
+		started = true
+		emptyRows = 0
+
+		for x := 0; x < end; {
+			state := g.grid[x][y]
+			run := 1
+			for x+run < end && g.grid[x+run][y] == state {
+				run++
+			}
+			tag := byte('.')
+			if state != EMPTY {
+				tag = 'A' + state - 1
+			}
+			put(run, tag)
+			x += run
+		}
+	}
+	put(1, '!')
+	bw.WriteByte('\n')
+	return bw.Flush()
+}
+
+// SaveRLE writes the board to path with WriteRLE. The file is written under
+// a temporary name first so a crash never leaves a half written one.
+func (g *Game) SaveRLE(path string) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = g.WriteRLE(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// resumeGame continues the run saved in an RLE snapshot, at the size,
+// generation and, unless -rule says otherwise, the rule saved in it
+func resumeGame(path string) (*Game, error) {
+	pattern, err := LoadRLE(path)
+	if err != nil {
+		return nil, err
+	}
+	if (flagSet("width") && *width != pattern.Width) || (flagSet("height") && *height != pattern.Height) {
+		return nil, fmt.Errorf("snapshot %s is %dx%d, not %dx%d", path, pattern.Width, pattern.Height, *width, *height)
+	}
+	if err := checkMemory(pattern.Width, pattern.Height); err != nil {
+		return nil, err
+	}
+
+	game, err := newEmptyGame(pattern.Width, pattern.Height, gridBuffers())
+	if err != nil {
+		return nil, err
+	}
+	game.rule, err = startRule(pattern.Rule)
+	if err != nil {
+		return nil, err
+	}
+	for _, cell := range pattern.Cells {
+		game.grid[cell.X][cell.Y] = cell.State
+	}
+	game.generation = pattern.Generation
+	game.countPopulation()
+	return game, nil
+}
+
+// Snapshotter saves the board every few generations and deletes all but the
+// newest few snapshots it saved.
+type Snapshotter struct {
+	dir   string
+	every int
+	keep  int
+	saved []string
+}
+
+func NewSnapshotter(dir string, every, keep int) *Snapshotter {
+	return &Snapshotter{dir: dir, every: every, keep: keep}
+}
+
+// Save is a GenerationFunc
+func (s *Snapshotter) Save(g *Game, gen int) error {
+	if gen%s.every != 0 {
+		return nil
+	}
+	path := filepath.Join(s.dir, fmt.Sprintf("golife-gen%09d.rle", gen))
+	if err := g.SaveRLE(path); err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	slog.Debug("snapshot saved", "path", path)
+	s.saved = append(s.saved, path)
+	for len(s.saved) > s.keep {
+		if err := os.Remove(s.saved[0]); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("snapshot: %w", err)
+		}
+		s.saved = s.saved[1:]
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+// roundTripRLE writes g with WriteRLE and reads it back into a game of the same size
+func roundTripRLE(t *testing.T, g *Game) (*Game, *Pattern) {
+	t.Helper()
+	var b bytes.Buffer
+	if err := g.WriteRLE(&b); err != nil {
+		t.Fatal(err)
+	}
+	p, err := ParseRLE(&b)
+	if err != nil {
+		t.Fatalf("%v in\n%s", err, b.String())
+	}
+	back := makeGame(g.width, g.height)
+	for _, cell := range p.Cells {
+		back.grid[cell.X][cell.Y] = cell.State
+	}
+	return back, p
+}
+
+func TestRLERoundTrip(t *testing.T) {
+	g, err := NewGame(100, 37, rand.New(rand.NewSource(3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 5 {
+		g.Update()
+		g.Swap()
+	}
+	back, p := roundTripRLE(t, g)
+	if !back.Equal(g) {
+		t.Error("the board read back differs")
+	}
+	if p.Rule != g.rule.String() || p.Generation != 5 || p.Width != 100 || p.Height != 37 {
+		t.Errorf("header read back as %dx%d, rule %q, generation %d", p.Width, p.Height, p.Rule, p.Generation)
+	}
+}
+
+func TestRLERoundTripEmptyRows(t *testing.T) {
+	for _, rows := range [][]int{{0}, {3}, {3, 4}, {1, 5}, {0, 6}, {6}, {}} {
+		g := makeGame(5, 7)
+		for _, y := range rows {
+			g.grid[1][y] = BLUE
+			g.grid[2][y] = ORANGE
+		}
+		if back, _ := roundTripRLE(t, g); !back.Equal(g) {
+			var b bytes.Buffer
+			g.WriteRLE(&b)
+			t.Errorf("live rows %v moved, written as\n%s", rows, b.String())
+		}
+	}
+}
+
+func TestResumeRoundTrip(t *testing.T) {
+	g, err := NewGame(50, 40, rand.New(rand.NewSource(9)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 7 {
+		g.Update()
+		g.Swap()
+	}
+	path := filepath.Join(t.TempDir(), "snapshot.rle")
+	if err := g.SaveRLE(path); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(r string) { *resume = r }(*resume)
+	*resume = path
+	resumed, err := newStartGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Generation() != 7 || resumed.width != 50 || resumed.height != 40 {
+		t.Fatalf("resumed %dx%d at generation %d", resumed.width, resumed.height, resumed.Generation())
+	}
+
+	// Both go on exactly alike
+	for range 10 {
+		g.Update()
+		g.Swap()
+		resumed.Update()
+		resumed.Swap()
+		if !resumed.Equal(g) || resumed.population != g.population {
+			t.Fatalf("the resumed game differs at generation %d", g.Generation())
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// loadScene sets the flags listed in a JSON scene file, an object keyed by
+// flag name like {"rule": "B3/S23", "width": 200, "pattern": "gun.rle"}.
+// Flags in given, from the command line or the environment, keep their
+// value. Keys that are not flags are reported together.
+func loadScene(path string, given map[string]bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var scene map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&scene); err != nil {
+		return fmt.Errorf("scene %s: %w", path, err)
+	}
+
+	keys := make([]string, 0, len(scene))
+	for key := range scene {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var unknown []string
+	for _, key := range keys {
+		if flag.Lookup(key) == nil || key == "scene" {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("scene %s: unknown keys %s", path, strings.Join(unknown, ", "))
+	}
+
+	for _, key := range keys {
+		if given[key] {
+			continue
+		}
+		var value string
+		switch v := scene[key].(type) {
+		case string:
+			value = v
+		case json.Number:
+			value = v.String()
+		case bool:
+			value = fmt.Sprint(v)
+		default:
+			return fmt.Errorf("scene %s: %s must be a string, number or boolean", path, key)
+		}
+		if err := flag.Set(key, value); err != nil {
+			return fmt.Errorf("scene %s: %s: %w", path, key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSceneKeepsGivenFlags(t *testing.T) {
+	defer func(w, h int) { *width, *height = w, h }(*width, *height)
+	path := filepath.Join(t.TempDir(), "scene.json")
+	if err := os.WriteFile(path, []byte(`{"width": 321, "height": 123}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// As if -width came from the command line or GOLIFE_WIDTH
+	*width = 50
+	if err := loadScene(path, map[string]bool{"width": true}); err != nil {
+		t.Fatal(err)
+	}
+	if *width != 50 || *height != 123 {
+		t.Errorf("got width %d and height %d, want 50 and 123", *width, *height)
+	}
+}
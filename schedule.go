@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Schedule decides how Update shares the columns of the grid between workers
+type Schedule int
+
+const (
+	Tiles  Schedule = iota // workers take small tiles from a shared queue
+	Static                 // one equal range of columns per worker
+)
+
+// Tiles each worker gets on average under the Tiles schedule, more balance
+// busy regions better but cost more trips to the queue
+const TILES_PER_WORKER = 8
+
+func parseSchedule(name string) (Schedule, error) {
+	switch name {
+	case "tiles":
+		return Tiles, nil
+	case "static":
+		return Static, nil
+	}
+	return 0, fmt.Errorf("unknown schedule %q, expected tiles or static", name)
+}
+
+// TileQueue hands out ranges of columns to workers until the grid is
+// covered. A worker that finishes early takes the next tile, so columns
+// with many live cells do not hold up the others.
+type TileQueue struct {
+	next  atomic.Int64
+	tile  int
+	start int
+	end   int
+}
+
+// NewTileQueue shares the columns from start to end-1
+func NewTileQueue(start, end, workers int) *TileQueue {
+	return &TileQueue{
+		tile:  max(1, (end-start)/(workers*TILES_PER_WORKER)),
+		start: start,
+		end:   end,
+	}
+}
+
+// Take returns the next range of columns, ok is false once all are taken
+func (q *TileQueue) Take() (start, end int, ok bool) {
+	start = q.start + int(q.next.Add(1)-1)*q.tile
+	if start >= q.end {
+		return 0, 0, false
+	}
+	return start, min(start+q.tile, q.end), true
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// denseCornerGame fills only the leftmost eighth of the columns, so under
+// Static one worker gets all the live cells
+func denseCornerGame(t testing.TB, width, height int, schedule Schedule, workers int) *Game {
+	t.Helper()
+	g, err := newEmptyGame(width, height, DoubleBuffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.fillSeeded(3, func(x, y int) bool { return x < g.width/8 })
+	g.schedule = schedule
+	g.workers = workers
+	return g
+}
+
+func TestSchedulesAgree(t *testing.T) {
+	const GENERATIONS = 30
+	for _, workers := range []int{1, 3, 8, 64} {
+		static, tiles := denseCornerGame(t, 256, 64, Static, workers), denseCornerGame(t, 256, 64, Tiles, workers)
+		for range GENERATIONS {
+			static.Update()
+			static.SwapAndCount()
+			tiles.Update()
+			tiles.SwapAndCount()
+		}
+		if !tiles.Equal(static) {
+			t.Errorf("%d workers: tiles and static grids differ after %d generations", workers, GENERATIONS)
+		}
+		if tiles.population != static.population || tiles.changed != static.changed {
+			t.Errorf("%d workers: tiles counted %v and %d changed, static %v and %d",
+				workers, tiles.population, tiles.changed, static.population, static.changed)
+		}
+	}
+}
+
+// BenchmarkSchedule never swaps, so every iteration updates the same
+// generation and the live cells stay in the corner
+func BenchmarkSchedule(b *testing.B) {
+	for _, schedule := range []struct {
+		name     string
+		schedule Schedule
+	}{
+		{"tiles", Tiles},
+		{"static", Static},
+	} {
+		for _, workers := range []int{4, 8} {
+			b.Run(fmt.Sprintf("%s/workers=%d", schedule.name, workers), func(b *testing.B) {
+				g := denseCornerGame(b, 1024, 256, schedule.schedule, workers)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for range b.N {
+					g.Update()
+				}
+			})
+		}
+	}
+}
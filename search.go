@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"math/rand"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Side of the board each soup of -search runs on unless -width/-height are given
+const SEARCH_SIZE = 64
+
+// CycleDetector spots a board that repeats itself, possibly moved. It keeps
+// a hash of the bounding box of the non-empty cells of every generation it
+// saw, so a repeat is found the first generation it happens. Hashes are not
+// verified, a collision would report a false cycle.
+type CycleDetector struct {
+	seen map[uint64]cycleSighting
+}
+
+type cycleSighting struct {
+	generation int
+	x, y       int
+}
+
+// Cycle is a repeat found by CycleDetector: the board at Generation is the
+// one Period generations earlier moved by DX, DY. Bounds is where its
+// non-empty cells lie.
+type Cycle struct {
+	Generation, Period int
+	DX, DY             int
+	X, Y, W, H         int
+}
+
+func NewCycleDetector() *CycleDetector {
+	return &CycleDetector{seen: map[uint64]cycleSighting{}}
+}
+
+// Observe records the current generation and reports whether it repeats an
+// earlier one. An empty board is a cycle of period 1.
+func (d *CycleDetector) Observe(g *Game) (Cycle, bool) {
+	x0, y0, w, h := g.Bounds()
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%dx%d:", w, h)
+	column := make([]byte, h)
+	for x := x0; x < x0+w; x++ {
+		copy(column, g.grid[x][y0:y0+h])
+		hash.Write(column)
+	}
+	key := hash.Sum64()
+
+	if prev, ok := d.seen[key]; ok {
+		return Cycle{
+			Generation: g.generation, Period: g.generation - prev.generation,
+			DX: x0 - prev.x, DY: y0 - prev.y,
+			X: x0, Y: y0, W: w, H: h,
+		}, true
+	}
+	d.seen[key] = cycleSighting{g.generation, x0, y0}
+	return Cycle{}, false
+}
+
+// Bounds returns the smallest rectangle holding every non-empty cell, all
+// zero for an empty board
+func (g *Game) Bounds() (x, y, w, h int) {
+	minX, minY, maxX, maxY := g.width, g.height, -1, -1
+	for cx := range g.width {
+		for cy, state := range g.grid[cx] {
+			if state == EMPTY {
+				continue
+			}
+			minX, maxX = min(minX, cx), max(maxX, cx)
+			minY, maxY = min(minY, cy), max(maxY, cy)
+		}
+	}
+	if maxX < 0 {
+		return 0, 0, 0, 0
+	}
+	return minX, minY, maxX - minX + 1, maxY - minY + 1
+}
+
+// searchSoup runs one random soup until its board repeats or maxGenerations
+// pass. The soup string is what -soup takes to start from it again.
+func searchSoup(seed int64, maxGenerations int) (soupText string, game *Game, cycle Cycle, found bool, err error) {
+	data := make([]byte, SOUP_SIZE*SOUP_SIZE/8)
+	rand.New(rand.NewSource(seed)).Read(data)
+	soupText = base64.StdEncoding.EncodeToString(data)
+	pattern, err := ParseSoup(soupText)
+	if err != nil {
+		return
+	}
+
+	w, h := SEARCH_SIZE, SEARCH_SIZE
+	if flagSet("width") {
+		w = *width
+	}
+	if flagSet("height") {
+		h = *height
+	}
+	game, err = newEmptyGame(w, h, gridBuffers())
+	if err != nil {
+		return
+	}
+	if game.rule, err = startRule(""); err != nil {
+		return
+	}
+	if err = configureGame(game); err != nil {
+		return
+	}
+	// The soups already run in parallel
+	game.workers = 1
+	game.Stamp(pattern.Game(), (w-SOUP_SIZE)/2, (h-SOUP_SIZE)/2, Overwrite)
+	game.countPopulation()
+
+	detector := NewCycleDetector()
+	for range maxGenerations {
+		if cycle, found = detector.Observe(game); found {
+			return
+		}
+		game.Update()
+		game.Swap()
+	}
+	return
+}
+
+// runSearch runs count soups seeded from -seed on one goroutine per CPU and
+// reports every one that ends in a cycle other than a still or empty board
+func runSearch(count int) error {
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	slog.Info("searching", "soups", count, "seed", *seed)
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		found    int
+		wg       sync.WaitGroup
+	)
+	next := make(chan int64)
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for soupSeed := range next {
+				soupText, game, cycle, ok, err := searchSoup(soupSeed, *searchGenerations)
+				interesting := ok && (cycle.Period > 1 || cycle.DX != 0 || cycle.DY != 0)
+				if err == nil && interesting && *searchSave != "" {
+					path := filepath.Join(*searchSave, fmt.Sprintf("soup-%d-p%d.rle", soupSeed, cycle.Period))
+					err = game.SaveRLE(path)
+				}
+
+				mu.Lock()
+				switch {
+				case err != nil:
+					if firstErr == nil {
+						firstErr = err
+					}
+				case interesting:
+					found++
+					fmt.Printf("seed %d soup %s: period %d moving %d,%d after %d generations, %dx%d at %d,%d\n",
+						soupSeed, soupText, cycle.Period, cycle.DX, cycle.DY, cycle.Generation,
+						cycle.W, cycle.H, cycle.X, cycle.Y)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range count {
+		next <- *seed + int64(i)
+	}
+	close(next)
+	wg.Wait()
+
+	slog.Info("search done", "soups", count, "found", found)
+	return firstErr
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+)
+
+// ImageThresholds decide the state a pixel of a seed image becomes, all on a 0 to 1 scale.
+// A pixel is ORANGE when red exceeds blue by more than Warm and it is not
+// brighter than Light, otherwise BLUE when its luminance is below Dark,
+// otherwise EMPTY.
+type ImageThresholds struct {
+	Dark  float64
+	Light float64
+	Warm  float64
+}
+
+func loadImage(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	return img, err
+}
+
+// SeedImage sets every cell from the image scaled to the grid, nearest
+// neighbor, using th to pick the states
+func (g *Game) SeedImage(img image.Image, th ImageThresholds) {
+	bounds := img.Bounds()
+	for x := range g.width {
+		for y := range g.height {
+			px := bounds.Min.X + x*bounds.Dx()/g.width
+			py := bounds.Min.Y + y*bounds.Dy()/g.height
+			r, gr, b, _ := img.At(px, py).RGBA()
+
+			red, green, blue := float64(r)/0xFFFF, float64(gr)/0xFFFF, float64(b)/0xFFFF
+			luminance := 0.299*red + 0.587*green + 0.114*blue
+
+			switch {
+			case red-blue > th.Warm && luminance <= th.Light:
+				g.grid[x][y] = ORANGE
+			case luminance < th.Dark:
+				g.grid[x][y] = BLUE
+			default:
+				g.grid[x][y] = EMPTY
+			}
+		}
+	}
+	g.resetState()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Oscillators -selftest runs and their periods
+var selfTests = []struct {
+	preset string
+	period int
+}{
+	{"blinker", 2},
+	{"toad", 2},
+	{"pulsar", 3},
+}
+
+// Periods each -selftest oscillator runs for
+const SELFTEST_PERIODS = 5
+
+// runSelfTest runs every oscillator of selfTests in the classic engine under
+// B3/S23, placed across the corner of a torus so every generation wraps
+// around both edges. It writes PASS or FAIL for each to w and fails when any
+// did not return to its start after every period or changed in between.
+func runSelfTest(w io.Writer) error {
+	failed := 0
+	for _, test := range selfTests {
+		err := selfTestOscillator(test.preset, test.period)
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "FAIL %s: %v\n", test.preset, err)
+			continue
+		}
+		fmt.Fprintf(w, "PASS %s (period %d)\n", test.preset, test.period)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d self tests failed", failed, len(selfTests))
+	}
+	return nil
+}
+
+func selfTestOscillator(preset string, period int) error {
+	pattern, err := LoadPreset(preset)
+	if err != nil {
+		return err
+	}
+	// Room for the oscillator to grow without touching itself across the wrap
+	game, err := newEmptyGame(pattern.Width+8, pattern.Height+8, gridBuffers())
+	if err != nil {
+		return err
+	}
+	game.rule = mustParseRule("B3/S23")
+	game.classic = true
+	game.workers = *workers
+	game.Stamp(pattern.Game(), game.width-pattern.Width/2, game.height-pattern.Height/2, Overwrite)
+
+	start := makeGame(game.width, game.height)
+	start.Stamp(game, 0, 0, Overwrite)
+	if start.Live() == 0 {
+		return errors.New("no live cells to start with")
+	}
+	for gen := 1; gen <= period*SELFTEST_PERIODS; gen++ {
+		game.Update()
+		game.SwapAndCount()
+		if back := game.Equal(start); back != (gen%period == 0) {
+			if back {
+				return fmt.Errorf("back to the start after generation %d", gen)
+			}
+			return fmt.Errorf("not back to the start after generation %d", gen)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"log/slog"
+	"slices"
+)
+
+const (
+	// Longest spaceship period the tracker looks for
+	SHIP_MAX_PERIOD = 16
+	// Larger components are not followed, few spaceships are this big
+	SHIP_MAX_CELLS = 256
+)
+
+// ShipTracker follows connected components from one generation to the next
+// and logs the ones that keep their shape while moving in a straight line.
+// A component is the same one as in the previous generation when it covers
+// any cell that one covered, which holds for anything moving at most one
+// cell per generation.
+type ShipTracker struct {
+	labels, prevLabels []int32
+	// Track of every component by label-1, for the current and previous generation
+	tracks, prevTracks []*shipTrack
+}
+
+type shipTrack struct {
+	seen     []shipSighting
+	reported bool
+	taken    bool
+}
+
+// Shape hash and top left corner of a component in one generation
+type shipSighting struct {
+	hash uint64
+	x, y int
+}
+
+func NewShipTracker(g *Game) *ShipTracker {
+	return &ShipTracker{
+		labels:     make([]int32, g.width*g.height),
+		prevLabels: make([]int32, g.width*g.height),
+	}
+}
+
+// Observe is a GenerationFunc
+func (t *ShipTracker) Observe(g *Game, gen int) error {
+	components := g.labelComponents(t.labels)
+	t.tracks = t.tracks[:0]
+	for _, c := range components {
+		track := t.follow(g, c)
+		t.tracks = append(t.tracks, track)
+		if len(c.Cells) > SHIP_MAX_CELLS {
+			track.seen = track.seen[:0]
+			continue
+		}
+		track.seen = append(track.seen, sight(c, g.width, g.height))
+		if len(track.seen) > 2*SHIP_MAX_PERIOD+1 {
+			track.seen = track.seen[1:]
+		}
+		if track.reported {
+			continue
+		}
+		if period, dx, dy, ok := track.motion(g.width, g.height); ok {
+			track.reported = true
+			last := track.seen[len(track.seen)-1]
+			slog.Info("spaceship", "generation", gen, "direction", direction(dx, dy),
+				"dx", dx, "dy", dy, "period", period, "cells", len(c.Cells), "x", last.x, "y", last.y)
+		}
+	}
+	t.labels, t.prevLabels = t.prevLabels, t.labels
+	t.tracks, t.prevTracks = t.prevTracks, t.tracks
+	for _, track := range t.prevTracks {
+		track.taken = false
+	}
+	return nil
+}
+
+// follow returns the track of the component of the previous generation c
+// overlaps, or a new one. A component that split continues in one part only.
+func (t *ShipTracker) follow(g *Game, c Component) *shipTrack {
+	for _, cell := range c.Cells {
+		label := t.prevLabels[cell.GX*g.height+cell.GY]
+		if label == 0 || int(label) > len(t.prevTracks) {
+			continue
+		}
+		if track := t.prevTracks[label-1]; !track.taken {
+			track.taken = true
+			return track
+		}
+	}
+	return &shipTrack{taken: true}
+}
+
+// sight hashes the shape of c and finds its top left corner on the grid
+func sight(c Component, width, height int) shipSighting {
+	minX, minY := c.Cells[0].X, c.Cells[0].Y
+	for _, cell := range c.Cells {
+		minX, minY = min(minX, cell.X), min(minY, cell.Y)
+	}
+	offsets := make([][2]int, len(c.Cells))
+	for i, cell := range c.Cells {
+		offsets[i] = [2]int{cell.X - minX, cell.Y - minY}
+	}
+	slices.SortFunc(offsets, func(a, b [2]int) int {
+		if a[0] != b[0] {
+			return a[0] - b[0]
+		}
+		return a[1] - b[1]
+	})
+	hash := fnv.New64a()
+	for _, o := range offsets {
+		binary.Write(hash, binary.LittleEndian, [2]int32{int32(o[0]), int32(o[1])})
+	}
+	x := ((c.Cells[0].GX-c.Cells[0].X+minX)%width + width) % width
+	y := ((c.Cells[0].GY-c.Cells[0].Y+minY)%height + height) % height
+	return shipSighting{hash.Sum64(), x, y}
+}
+
+// motion looks for the shortest period after which the shape came back,
+// twice in a row, moved by the same non-zero step both times
+func (track *shipTrack) motion(width, height int) (period, dx, dy int, ok bool) {
+	last := len(track.seen) - 1
+	for p := 1; p <= SHIP_MAX_PERIOD && last-2*p >= 0; p++ {
+		a, b, c := track.seen[last], track.seen[last-p], track.seen[last-2*p]
+		if a.hash != b.hash || b.hash != c.hash {
+			continue
+		}
+		dx, dy = torusStep(b.x, a.x, width), torusStep(b.y, a.y, height)
+		if dx == 0 && dy == 0 {
+			return 0, 0, 0, false // an oscillator or still life
+		}
+		if torusStep(c.x, b.x, width) == dx && torusStep(c.y, b.y, height) == dy {
+			return p, dx, dy, true
+		}
+		return 0, 0, 0, false
+	}
+	return 0, 0, 0, false
+}
+
+// torusStep is the shortest step from a to b around a ring of size cells
+func torusStep(a, b, size int) int {
+	d := ((b-a)%size + size) % size
+	if d > size/2 {
+		d -= size
+	}
+	return d
+}
+
+// direction names a step with the grid y axis pointing down
+func direction(dx, dy int) string {
+	name := ""
+	switch {
+	case dy < 0:
+		name = "north"
+	case dy > 0:
+		name = "south"
+	}
+	switch {
+	case dx > 0 && name != "":
+		name += "-east"
+	case dx > 0:
+		name = "east"
+	case dx < 0 && name != "":
+		name += "-west"
+	case dx < 0:
+		name = "west"
+	}
+	return name
+}
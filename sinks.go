@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+)
+
+// FanOut writes the stream to several sinks, each getting every byte in the
+// same order. A sink whose write fails is dropped with a warning and never
+// written again, even mid-frame, so the others always get whole frames. The
+// run only fails once every sink has. Sinks are written one after another,
+// so a slow one holds up the rest.
+type FanOut struct {
+	sinks   []namedSink
+	closers []io.Closer
+}
+
+type namedSink struct {
+	name string
+	w    io.Writer
+}
+
+// Add makes w a sink of the fan-out, name is used in the warning when it fails
+func (f *FanOut) Add(name string, w io.Writer) {
+	f.sinks = append(f.sinks, namedSink{name, w})
+}
+
+// Write sends p to every sink still working
+func (f *FanOut) Write(p []byte) (int, error) {
+	var lastErr error
+	working := f.sinks[:0]
+	for _, sink := range f.sinks {
+		if _, err := sink.w.Write(p); err != nil {
+			slog.Warn("output sink dropped", "sink", sink.name, "err", err)
+			lastErr = err
+			continue
+		}
+		working = append(working, sink)
+	}
+	f.sinks = working
+	if len(working) == 0 {
+		return 0, fmt.Errorf("every output sink failed, the last with: %w", lastErr)
+	}
+	return len(p), nil
+}
+
+// Open adds the sinks -tee names, comma separated: tcp:host:port connects
+// to a listening consumer, anything else is a file to create. Close closes
+// them again.
+func (f *FanOut) Open(list string) error {
+	for _, spec := range strings.Split(list, ",") {
+		var sink io.WriteCloser
+		var err error
+		if addr, ok := strings.CutPrefix(spec, "tcp:"); ok {
+			sink, err = net.Dial("tcp", addr)
+		} else {
+			sink, err = os.Create(spec)
+		}
+		if err != nil {
+			return fmt.Errorf("-tee: %w", err)
+		}
+		f.closers = append(f.closers, sink)
+		f.Add(spec, sink)
+	}
+	return nil
+}
+
+// Close closes the sinks Open added, dropped ones included
+func (f *FanOut) Close() error {
+	var err error
+	for _, c := range f.closers {
+		if closeErr := c.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
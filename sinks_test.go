@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var errSinkFull = errors.New("sink full")
+
+// limitedSink takes n bytes and fails every write after that
+type limitedSink struct {
+	n   int
+	buf bytes.Buffer
+}
+
+func (s *limitedSink) Write(p []byte) (int, error) {
+	if s.buf.Len()+len(p) > s.n {
+		return 0, errSinkFull
+	}
+	return s.buf.Write(p)
+}
+
+func TestFanOutDropsFailedSink(t *testing.T) {
+	defer func(p Protocol) { protocol = p }(protocol)
+	protocol = SparsePixelsHeader
+
+	var good bytes.Buffer
+	bad := &limitedSink{n: 1000}
+	fan := &FanOut{}
+	fan.Add("good", &good)
+	fan.Add("bad", bad)
+
+	g, _ := newEmptyGame(40, 40, DoubleBuffer)
+	g.fillSeeded(1, nil)
+	ref, _ := newEmptyGame(40, 40, DoubleBuffer)
+	ref.fillSeeded(1, nil)
+	var want bytes.Buffer
+	for range 20 {
+		if err := g.writeFrame(fan); err != nil {
+			t.Fatalf("the run failed with a sink still working: %v", err)
+		}
+		ref.writeFrame(&want)
+		for _, game := range []*Game{g, ref} {
+			game.Update()
+			game.Swap()
+		}
+	}
+	if !bytes.Equal(good.Bytes(), want.Bytes()) {
+		t.Error("the working sink did not get the whole stream")
+	}
+	if len(fan.sinks) != 1 || fan.sinks[0].name != "good" {
+		t.Errorf("sinks left: %v", fan.sinks)
+	}
+	if bad.buf.Len() == 0 {
+		t.Error("the failing sink never got anything")
+	}
+}
+
+func TestFanOutFailsWhenEverySinkHas(t *testing.T) {
+	first, second := &limitedSink{n: 2}, &limitedSink{n: 4}
+	fan := &FanOut{}
+	fan.Add("first", first)
+	fan.Add("second", second)
+	for i := range 2 {
+		if _, err := fan.Write([]byte{1, 2}); err != nil {
+			t.Fatalf("write %d: %v", i+1, err)
+		}
+	}
+	if _, err := fan.Write([]byte{3, 4}); err == nil || !errors.Is(err, errSinkFull) {
+		t.Fatalf("got %v, want the last sink's error", err)
+	}
+	if first.buf.Len() != 2 || second.buf.Len() != 4 {
+		t.Errorf("sinks got %d and %d bytes", first.buf.Len(), second.buf.Len())
+	}
+}
+
+func TestFanOutOpenFiles(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.bin"), filepath.Join(dir, "b.bin")
+	fan := &FanOut{}
+	if err := fan.Open(a + "," + b); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fan.Write([]byte("frame")); err != nil {
+		t.Fatal(err)
+	}
+	if err := fan.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{a, b} {
+		if data, err := os.ReadFile(path); err != nil || string(data) != "frame" {
+			t.Errorf("%s holds %q, %v", path, data, err)
+		}
+	}
+	if err := (&FanOut{}).Open(filepath.Join(dir, "missing", "c.bin")); err == nil {
+		t.Error("opened a file in a missing directory")
+	}
+}
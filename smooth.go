@@ -0,0 +1,72 @@
+package main
+
+import (
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// drawSmooth shows -smooth extra frames after a generation, each blending
+// the colors of the previous generation a step further towards the current
+// one. With -fps they are spread over the time until the next generation is
+// due, without it they are drawn as fast as the window takes them.
+func (game *Game) drawSmooth(renderer *sdl.Renderer) {
+	n := game.smoothFrames
+	var step time.Duration
+	if game.limiter != nil {
+		step = game.limiter.interval / time.Duration(n+1)
+	}
+	start := time.Now()
+	for i := 1; i <= n && !game.quit; i++ {
+		due := start.Add(time.Duration(i-1) * step)
+		for left := time.Until(due); left > 0; left = time.Until(due) {
+			game.wait(left)
+		}
+		game.blend = float64(i) / float64(n+1)
+		game.visualize(renderer)
+	}
+	game.blend = 0
+}
+
+// DrawBlend draws every cell in a color t of the way from the color of its
+// state in the previous generation, which nextGrid holds between Swap and the
+// next Update, to the color of its current state. Cells are grouped by their
+// pair of states, so at most one color per pair is set.
+func (g *Game) DrawBlend(renderer *sdl.Renderer, t float64) {
+	const states = MAX_STATE + 1
+	if len(g.blendRects) != states*states {
+		g.blendRects = make([][]sdl.Rect, states*states)
+	}
+	for i := range g.blendRects {
+		g.blendRects[i] = g.blendRects[i][:0]
+	}
+
+	z := int32(*zoom)
+	for x := range g.width {
+		for y := range g.height {
+			pair := int(g.nextGrid[x][y])*states + int(g.grid[x][y])
+			g.blendRects[pair] = append(g.blendRects[pair], sdl.Rect{X: int32(x) * z, Y: int32(y) * z, W: z, H: z})
+		}
+	}
+
+	// The background is already cleared to the EMPTY color
+	for pair, rects := range g.blendRects {
+		color := mixColor(windowPalette[pair/states], windowPalette[pair%states], t)
+		if len(rects) == 0 || color == windowPalette[EMPTY] {
+			continue
+		}
+		setDrawColor(renderer, color)
+		inBatches(rects, renderer.FillRects)
+	}
+}
+
+// mixColor is the color t of the way from one color to another
+func mixColor(from, to uint32, t float64) uint32 {
+	var color uint32
+	for shift := 0; shift <= 16; shift += 8 {
+		a := float64(from >> shift & 0xFF)
+		b := float64(to >> shift & 0xFF)
+		color |= uint32(a+(b-a)*t+0.5) << shift
+	}
+	return color
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Side of a soup, the 16x16 region apgsearch seeds
+const SOUP_SIZE = 16
+
+// ParseSoup decodes a soup: 32 bytes in base64, standard or URL alphabet,
+// padding optional. Byte j covers row j/2, columns 0-7 for even j and 8-15
+// for odd j, most significant bit first, the same bit order apgsearch uses
+// for its hashes. Set bits become BLUE.
+func ParseSoup(text string) (*Pattern, error) {
+	text = strings.TrimRight(strings.TrimSpace(text), "=")
+	text = strings.NewReplacer("-", "+", "_", "/").Replace(text)
+	data, err := base64.RawStdEncoding.DecodeString(text)
+	if err != nil {
+		return nil, fmt.Errorf("bad soup: %w", err)
+	}
+	if len(data) != SOUP_SIZE*SOUP_SIZE/8 {
+		return nil, fmt.Errorf("bad soup: %d bytes, expected %d", len(data), SOUP_SIZE*SOUP_SIZE/8)
+	}
+
+	pattern := &Pattern{Width: SOUP_SIZE, Height: SOUP_SIZE}
+	for j, b := range data {
+		for k := range 8 {
+			if b&(0x80>>k) != 0 {
+				pattern.Cells = append(pattern.Cells, Cell{X: k + 8*(j%2), Y: j / 2, State: BLUE})
+			}
+		}
+	}
+	return pattern, nil
+}
+
+// FormatSoup encodes the live cells of the 16x16 region at x, y as ParseSoup reads them
+func (g *Game) FormatSoup(x, y int) string {
+	data := make([]byte, SOUP_SIZE*SOUP_SIZE/8)
+	for j := range data {
+		for k := range 8 {
+			nx, ny, ok := g.resolvePlaced(x+k+8*(j%2), y+j/2)
+			if ok && (g.grid[nx][ny] == BLUE || g.grid[nx][ny] == ORANGE) {
+				data[j] |= 0x80 >> k
+			}
+		}
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// loadSoup reads the soup given to -soup, - reads it from stdin
+func loadSoup(arg string) (*Pattern, error) {
+	if arg == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		arg = string(data)
+	}
+	return ParseSoup(arg)
+}
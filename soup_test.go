@@ -0,0 +1,55 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSoupRoundTrip(t *testing.T) {
+	g, err := NewGame(40, 40, rand.New(rand.NewSource(5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	soup := g.FormatSoup(3, 4)
+	p, err := ParseSoup(soup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back := p.Game().FormatSoup(0, 0); back != soup {
+		t.Errorf("%s read back as %s", soup, back)
+	}
+	for _, cell := range p.Cells {
+		if state := g.grid[3+cell.X][4+cell.Y]; state != BLUE && state != ORANGE {
+			t.Errorf("cell %d,%d is live in the soup but %d on the board", cell.X, cell.Y, state)
+		}
+	}
+}
+
+func TestParseSoupBitOrder(t *testing.T) {
+	// The first bit of the first byte and the last bit of the last one,
+	// with and without padding
+	for _, soup := range []string{
+		"gAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAE",
+		"gAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAE=",
+	} {
+		p, err := ParseSoup(soup)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(p.Cells) != 2 || p.Cells[0] != (Cell{0, 0, BLUE}) || p.Cells[1] != (Cell{15, 15, BLUE}) {
+			t.Errorf("%s read as %v", soup, p.Cells)
+		}
+	}
+	// A first byte of all ones in the URL alphabet
+	if p, err := ParseSoup("_wAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"); err != nil || len(p.Cells) != 8 {
+		t.Errorf("URL alphabet soup: %v", err)
+	}
+}
+
+func TestParseSoupErrors(t *testing.T) {
+	for _, soup := range []string{"", "AAAA", "not base64!"} {
+		if _, err := ParseSoup(soup); err == nil {
+			t.Errorf("%q parsed", soup)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+type StampMode int
+
+const (
+	Overwrite StampMode = iota // copy every cell of the source
+	Or                         // copy only BLUE and ORANGE cells
+)
+
+// Stamp copies src onto the grid with its top left corner at offsetX, offsetY.
+// Cells past an edge wrap around when the edge mode wraps that axis and are
+// dropped otherwise, reflecting edges included.
+func (g *Game) Stamp(src *Game, offsetX, offsetY int, mode StampMode) {
+	for x := range src.width {
+		for y := range src.height {
+			state := src.grid[x][y]
+			if mode == Or && state != BLUE && state != ORANGE {
+				continue
+			}
+
+			nx, ny, ok := g.resolvePlaced(offsetX+x, offsetY+y)
+			if !ok {
+				continue
+			}
+			g.grid[nx][ny] = state
+			if g.age != nil {
+				g.age[nx][ny] = 0
+			}
+			if g.aux != nil {
+				g.aux[nx][ny] = 0
+			}
+		}
+	}
+	g.countPopulation()
+}
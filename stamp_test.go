@@ -0,0 +1,73 @@
+package main
+
+import "testing"
+
+func TestStampAcrossSeams(t *testing.T) {
+	// Every cell of the source is set, so every one that lands is written
+	src := makeGame(3, 3)
+	for x := range 3 {
+		for y := range 3 {
+			src.grid[x][y] = BLUE + uint8(x+y)%(MAX_STATE-BLUE+1)
+		}
+	}
+	const SIZE, OFFSET_X, OFFSET_Y = 10, 8, 9
+	for _, c := range []struct {
+		edge         EdgeMode
+		wrapX, wrapY bool
+	}{
+		{Toroidal, true, true},
+		{WrapX, true, false},
+		{WrapY, false, true},
+		{Bounded, false, false},
+		{Reflecting, false, false},
+	} {
+		g, err := newEmptyGame(SIZE, SIZE, DoubleBuffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.edge = c.edge
+		g.EnableAge()
+		g.EnableAux(nil)
+		for x := range SIZE {
+			for y := range SIZE {
+				g.age[x][y] = 7
+				g.aux[x][y] = 7
+			}
+		}
+		g.Stamp(src, OFFSET_X, OFFSET_Y, Overwrite)
+
+		want := make(map[[2]int]uint8)
+		for x := range 3 {
+			for y := range 3 {
+				nx, ny := OFFSET_X+x, OFFSET_Y+y
+				if nx >= SIZE {
+					if !c.wrapX {
+						continue
+					}
+					nx -= SIZE
+				}
+				if ny >= SIZE {
+					if !c.wrapY {
+						continue
+					}
+					ny -= SIZE
+				}
+				want[[2]int{nx, ny}] = src.grid[x][y]
+			}
+		}
+		for x := range SIZE {
+			for y := range SIZE {
+				state, written := want[[2]int{x, y}]
+				if g.grid[x][y] != state {
+					t.Errorf("edge %d: cell %d,%d is %d, want %d", c.edge, x, y, g.grid[x][y], state)
+				}
+				if reset := g.age[x][y] == 0 && g.aux[x][y] == 0; reset != written {
+					t.Errorf("edge %d: cell %d,%d has age %d and aux %d after stamping", c.edge, x, y, g.age[x][y], g.aux[x][y])
+				}
+			}
+		}
+		if empty := g.population[EMPTY]; empty != SIZE*SIZE-len(want) {
+			t.Errorf("edge %d: %d EMPTY cells counted after stamping, want %d", c.edge, empty, SIZE*SIZE-len(want))
+		}
+	}
+}
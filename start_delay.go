@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Height in window pixels of the -start-countdown bar
+const COUNTDOWN_HEIGHT = 4
+
+var countdownColor = rgba(0xFF, 0x00, 0x33)
+
+// holdStart shows the board as it is for delay before the first generation
+// is computed, keeping the window responsive. It runs before the frame
+// limiter's first Wait, so the limiter starts counting afterwards instead of
+// catching up on the frames the hold took.
+func (game *Game) holdStart(ctx context.Context, renderer *sdl.Renderer, delay time.Duration) error {
+	end := time.Now().Add(delay)
+	defer func() { game.countdown = 0 }()
+	for !game.quit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		left := time.Until(end)
+		if left <= 0 {
+			return nil
+		}
+		if *visual {
+			if *startCountdown {
+				game.countdown = float64(left) / float64(delay)
+			}
+			game.visualize(renderer)
+		}
+		game.wait(min(left, 10*time.Millisecond))
+	}
+	return nil
+}
+
+// drawCountdown draws a bar along the bottom of the window that shrinks to
+// nothing as the start delay runs out
+func (game *Game) drawCountdown(renderer *sdl.Renderer) {
+	winW, winH, err := renderer.GetOutputSize()
+	if err != nil {
+		return
+	}
+	setDrawColor(renderer, countdownColor)
+	renderer.FillRect(&sdl.Rect{
+		X: 0, Y: winH - COUNTDOWN_HEIGHT,
+		W: int32(game.countdown * float64(winW)), H: COUNTDOWN_HEIGHT,
+	})
+}
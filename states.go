@@ -0,0 +1,48 @@
+package main
+
+import "strconv"
+
+// Cell states. A cell is EMPTY, live as BLUE or ORANGE, or decaying from DEAD
+// through DECAY_STAGES more states up to MAX_STATE. Every protocol, palette
+// and table indexed by state takes its values from here.
+const (
+	EMPTY  = 0
+	BLUE   = 1
+	ORANGE = 2
+	DEAD   = 3
+	// States a cell decays through after DEAD before it is EMPTY again
+	DECAY_STAGES = 3
+	MAX_STATE    = DEAD + DECAY_STAGES
+)
+
+// The checks below fail to compile when the states above stop making sense.
+// An array length that goes negative is a compile error.
+var (
+	// Each state is bigger than the one before, so none share a value and
+	// CellChange can tell decaying cells apart with cell >= DEAD
+	_ [BLUE - EMPTY - 1]struct{}
+	_ [ORANGE - BLUE - 1]struct{}
+	_ [DEAD - ORANGE - 1]struct{}
+	_ [DECAY_STAGES - 1]struct{}
+	// DenseCells and the CSV files write a state as one byte
+	_ [0xFF - MAX_STATE]struct{}
+	// The palette presets list colors for exactly three stages after DEAD
+	_ [DECAY_STAGES - 3]struct{}
+	_ [3 - DECAY_STAGES]struct{}
+)
+
+// Names of the cell states in reports
+var stateNames = nameStates()
+
+func nameStates() [MAX_STATE + 1]string {
+	names := [MAX_STATE + 1]string{
+		EMPTY:  "empty",
+		BLUE:   "blue",
+		ORANGE: "orange",
+		DEAD:   "dead",
+	}
+	for stage := 1; stage <= DECAY_STAGES; stage++ {
+		names[DEAD+stage] = "decay" + strconv.Itoa(stage)
+	}
+	return names
+}
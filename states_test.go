@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestStateNames(t *testing.T) {
+	want := []string{"empty", "blue", "orange", "dead"}
+	for stage := 1; stage <= DECAY_STAGES; stage++ {
+		want = append(want, "decay"+strconv.Itoa(stage))
+	}
+	if len(stateNames) != len(want) {
+		t.Fatalf("%d state names, want %d", len(stateNames), len(want))
+	}
+	seen := map[string]bool{}
+	for state, name := range stateNames {
+		if name != want[state] || seen[name] {
+			t.Errorf("state %d named %q, want %q", state, name, want[state])
+		}
+		seen[name] = true
+	}
+}
+
+func TestDecayReachesEmpty(t *testing.T) {
+	// A cell killed by the rule takes one generation per stage to clear,
+	// never landing on a live state on the way
+	cell := uint8(DEAD)
+	for range DECAY_STAGES {
+		cell = advanceDecay(cell, BLUE)
+		if cell <= DEAD || cell > MAX_STATE {
+			t.Fatalf("decayed into state %d", cell)
+		}
+	}
+	if cell = advanceDecay(cell, BLUE); cell != EMPTY {
+		t.Errorf("the last stage moved on to %d, want EMPTY", cell)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// StatsWriter appends one CSV line per generation: the generation, the
+// number of cells in each state, the time since the first line and
+// optionally the time since the previous line. Times are whole units.
+type StatsWriter struct {
+	file    *os.File
+	unit    time.Duration
+	genTime bool
+	start   time.Time
+	last    time.Time
+}
+
+func parseTimeUnit(name string) (time.Duration, error) {
+	switch name {
+	case "ns":
+		return time.Nanosecond, nil
+	case "ms":
+		return time.Millisecond, nil
+	}
+	return 0, fmt.Errorf("unknown time unit %q, expected ns or ms", name)
+}
+
+// NewStatsWriter creates the file and writes the header line
+func NewStatsWriter(path string, unit time.Duration, genTime bool) (*StatsWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := append([]string{"generation"}, stateNames[:]...)
+	columns = append(columns, "elapsed")
+	if genTime {
+		columns = append(columns, "gen_time")
+	}
+	if _, err := fmt.Fprintln(file, strings.Join(columns, ",")); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	now := time.Now()
+	return &StatsWriter{file: file, unit: unit, genTime: genTime, start: now, last: now}, nil
+}
+
+func (s *StatsWriter) Write(g *Game) error {
+	now := time.Now()
+
+	var line strings.Builder
+	fmt.Fprint(&line, g.Generation())
+	for _, n := range g.population {
+		fmt.Fprintf(&line, ",%d", n)
+	}
+	fmt.Fprintf(&line, ",%d", now.Sub(s.start)/s.unit)
+	if s.genTime {
+		fmt.Fprintf(&line, ",%d", now.Sub(s.last)/s.unit)
+	}
+	s.last = now
+
+	line.WriteByte('\n')
+	_, err := s.file.WriteString(line.String())
+	return err
+}
+
+func (s *StatsWriter) Close() error {
+	return s.file.Close()
+}
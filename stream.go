@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+)
+
+// First byte of the frame sent to a client before it is disconnected
+const STREAM_ERROR = 0xFF
+
+// How long a client has to send its protocol byte before it is dropped, so
+// one that connects and stays silent does not keep the next from being
+// accepted. Replaced by tests.
+var streamHandshakeTimeout = 5 * time.Second
+
+// StreamServer streams frames to a TCP client. A client connects and sends
+// one byte with the Protocol value it wants, golife then streams that protocol.
+// When the client goes away the frames are dropped until the next one connects.
+type StreamServer struct {
+	listener net.Listener
+	conn     net.Conn
+	protocol Protocol
+
+	// Clients accepted in the background, waiting for the next frame
+	next chan streamClient
+}
+
+type streamClient struct {
+	conn     net.Conn
+	protocol Protocol
+}
+
+func Listen(addr string) (*StreamServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamServer{listener: listener, next: make(chan streamClient, 1)}, nil
+}
+
+// Accept waits for a client that asks for a known protocol. Clients asking
+// for anything else get an error frame: STREAM_ERROR, the length of the
+// message as a little endian uint16 and the message.
+func (s *StreamServer) Accept() error {
+	client, err := s.accept()
+	if err != nil {
+		return err
+	}
+	s.conn = client.conn
+	s.protocol = client.protocol
+	return nil
+}
+
+func (s *StreamServer) accept() (streamClient, error) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return streamClient{}, err
+		}
+
+		var selector [1]byte
+		conn.SetReadDeadline(time.Now().Add(streamHandshakeTimeout))
+		if _, err := conn.Read(selector[:]); err != nil {
+			slog.Warn("dropped a client that sent no protocol", "client", conn.RemoteAddr(), "err", err)
+			conn.Close()
+			continue
+		}
+		conn.SetReadDeadline(time.Time{})
+
+		p := Protocol(selector[0])
+		if p == Off || !p.known() {
+			s.reject(conn, fmt.Sprintf("unknown protocol %d", p))
+			continue
+		}
+
+		slog.Info("streaming", "protocol", p, "client", conn.RemoteAddr())
+		return streamClient{conn, p}, nil
+	}
+}
+
+// Write sends p to the current client. A failed write drops the client and
+// starts waiting for a new one, frames are discarded in the meantime so the
+// game keeps running.
+func (s *StreamServer) Write(p []byte) (int, error) {
+	if s.conn == nil {
+		return len(p), nil
+	}
+	if _, err := s.conn.Write(p); err != nil {
+		slog.Warn("lost the client, waiting for the next one", "client", s.conn.RemoteAddr(), "err", err)
+		s.conn.Close()
+		s.conn = nil
+		go func() {
+			client, err := s.accept()
+			if err == nil {
+				s.next <- client
+			}
+		}()
+	}
+	return len(p), nil
+}
+
+// switchClient is a GenerationFunc that hands the stream to a client accepted
+// in the background. It runs between frames, so the client starts with a
+// whole one, and for the delta protocols one holding every non-empty cell.
+func (s *StreamServer) switchClient(g *Game, gen int) error {
+	select {
+	case client := <-s.next:
+		slog.Debug("switching the stream", "generation", gen, "client", client.conn.RemoteAddr())
+		s.conn = client.conn
+		s.protocol = client.protocol
+		protocol = client.protocol
+		g.deltaPrev = nil
+	default:
+	}
+	return nil
+}
+
+func (s *StreamServer) reject(conn net.Conn, message string) {
+	frame := []byte{STREAM_ERROR}
+	frame = binary.LittleEndian.AppendUint16(frame, uint16(len(message)))
+	frame = append(frame, message...)
+	if _, err := conn.Write(frame); err != nil {
+		slog.Warn("could not tell the client why it was rejected", "client", conn.RemoteAddr(), "err", err)
+	}
+	conn.Close()
+}
+
+func (s *StreamServer) Close() error {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	return s.listener.Close()
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// dialStream connects a client to s asking for p
+func dialStream(t *testing.T, s *StreamServer, p Protocol) net.Conn {
+	t.Helper()
+	conn, err := net.Dial("tcp", s.listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write([]byte{byte(p)}); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func TestStreamReconnect(t *testing.T) {
+	defer func(p Protocol) { protocol = p }(protocol)
+	s, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	first := dialStream(t, s, DenseCells)
+	if err := s.Accept(); err != nil {
+		t.Fatal(err)
+	}
+	first.Close()
+
+	// The closed client is only noticed once a write to it fails, and
+	// writes never fail for the game
+	frame := make([]byte, 1000)
+	deadline := time.Now().Add(2 * time.Second)
+	for s.conn != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("the closed client was never dropped")
+		}
+		if _, err := s.Write(frame); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	g := makeGame(4, 4)
+	g.deltaPrev = [][]uint8{{BLUE}}
+	second := dialStream(t, s, DeltaCells)
+	defer second.Close()
+	for s.conn == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("the next client was never switched to")
+		}
+		s.switchClient(g, 0)
+		time.Sleep(time.Millisecond)
+	}
+	if protocol != DeltaCells || s.protocol != DeltaCells {
+		t.Errorf("streaming %v, want the new client's %v", protocol, DeltaCells)
+	}
+	if g.deltaPrev != nil {
+		t.Error("the new client would get a delta against frames it never saw")
+	}
+
+	if _, err := s.Write([]byte("frame")); err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, 5)
+	if _, err := io.ReadFull(second, got); err != nil || string(got) != "frame" {
+		t.Errorf("the new client read %q, %v", got, err)
+	}
+}
+
+func TestStreamSilentClientDropped(t *testing.T) {
+	defer func(d time.Duration) { streamHandshakeTimeout = d }(streamHandshakeTimeout)
+	streamHandshakeTimeout = 50 * time.Millisecond
+	s, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// Connects but never says which protocol it wants
+	silent, err := net.Dial("tcp", s.listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	accepted := make(chan error, 1)
+	go func() { accepted <- s.Accept() }()
+	time.Sleep(2 * streamHandshakeTimeout)
+
+	second := dialStream(t, s, DenseCells)
+	defer second.Close()
+	select {
+	case err := <-accepted:
+		if err != nil || s.protocol != DenseCells {
+			t.Fatalf("accepted %v, %v", s.protocol, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the silent client kept the next one from being accepted")
+	}
+}
+
+func TestStreamRejectsUnknownProtocol(t *testing.T) {
+	s, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	go s.Accept()
+
+	conn := dialStream(t, s, Off)
+	defer conn.Close()
+	var head [3]byte
+	if _, err := io.ReadFull(conn, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	message := make([]byte, binary.LittleEndian.Uint16(head[1:]))
+	if _, err := io.ReadFull(conn, message); err != nil {
+		t.Fatal(err)
+	}
+	if head[0] != STREAM_ERROR || string(message) != "unknown protocol 0" {
+		t.Errorf("got frame %x %q", head[0], message)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+type svgRect struct {
+	X      int    `xml:"x,attr"`
+	Y      int    `xml:"y,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+	Fill   string `xml:"fill,attr"`
+}
+
+type svgImage struct {
+	Width  int       `xml:"width,attr"`
+	Height int       `xml:"height,attr"`
+	Rects  []svgRect `xml:"rect"`
+	Groups []struct {
+		Fill  string    `xml:"fill,attr"`
+		Rects []svgRect `xml:"rect"`
+	} `xml:"g"`
+}
+
+func TestWriteSVG(t *testing.T) {
+	g, err := newEmptyGame(10, 8, DoubleBuffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[[2]int]uint8{
+		{1, 1}: BLUE, {2, 1}: BLUE, {3, 1}: BLUE,
+		{5, 5}: ORANGE,
+		{7, 2}: DEAD + 1,
+	}
+	for cell, state := range want {
+		g.Set(cell[0], cell[1], state)
+	}
+
+	var b bytes.Buffer
+	if err := g.WriteSVG(&b); err != nil {
+		t.Fatal(err)
+	}
+	var img svgImage
+	if err := xml.Unmarshal(b.Bytes(), &img); err != nil {
+		t.Fatalf("%v in\n%s", err, b.String())
+	}
+	if img.Width != 10 || img.Height != 8 {
+		t.Errorf("image is %dx%d, want 10x8", img.Width, img.Height)
+	}
+	if len(img.Rects) != 1 || img.Rects[0].Fill != svgColor(pixelPalette[EMPTY]) {
+		t.Errorf("background %v, want one rect in the EMPTY color", img.Rects)
+	}
+
+	// One group per state on the board, each cell in its state's color
+	if len(img.Groups) != 3 {
+		t.Errorf("%d groups, want one each for blue, orange and the decaying cell", len(img.Groups))
+	}
+	found := 0
+	for _, group := range img.Groups {
+		for _, rect := range group.Rects {
+			state, ok := want[[2]int{rect.X, rect.Y}]
+			if !ok || group.Fill != svgColor(pixelPalette[state]) || rect.Width != 1 || rect.Height != 1 {
+				t.Errorf("unexpected cell %+v filled %s", rect, group.Fill)
+				continue
+			}
+			found++
+		}
+	}
+	if found != len(want) {
+		t.Errorf("%d of %d cells drawn", found, len(want))
+	}
+}
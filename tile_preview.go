@@ -0,0 +1,20 @@
+package main
+
+import "github.com/veandco/go-sdl2/sdl"
+
+// drawTiles draws the board nine times, 3x3 at a third of the scale or less
+// to fit the window, so patterns crossing the wrapping edges read as one.
+// The real board is the framed one in the middle.
+func (g *Game) drawTiles(renderer *sdl.Renderer, winW, winH int32) {
+	w, h := int32(g.width**zoom), int32(g.height**zoom)
+	scale := min(float32(winW)/float32(3*w), float32(winH)/float32(3*h))
+	renderer.SetScale(scale, scale)
+	for i := range int32(3) {
+		for j := range int32(3) {
+			g.drawInViewport(renderer, i*w, j*h)
+		}
+	}
+	setDrawColor(renderer, minimapView)
+	renderer.DrawRect(&sdl.Rect{X: w, Y: h, W: w, H: h})
+	renderer.SetScale(1, 1)
+}
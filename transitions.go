@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// Stands for a birth in Transitions, the color is picked from the neighbors
+const BORN = 0xFF
+
+// Transitions holds the next state of a cell for every state and live
+// neighbor count under a rule. Decaying cells do not look at the count.
+type Transitions [MAX_STATE + 1][9]uint8
+
+func newTransitions(rule Rule) *Transitions {
+	var t Transitions
+	for count := range 9 {
+		t[EMPTY][count] = EMPTY
+		if rule.Birth[count] {
+			t[EMPTY][count] = BORN
+		}
+		for _, live := range []uint8{BLUE, ORANGE} {
+			t[live][count] = DEAD
+			if rule.Survival[count] {
+				t[live][count] = live
+			}
+		}
+		for state := uint8(DEAD); state < MAX_STATE; state++ {
+			t[state][count] = state + 1
+		}
+		t[MAX_STATE][count] = EMPTY
+	}
+	return &t
+}
+
+// cellChangeTable is CellChange looking the next state up in t, which must
+// have been made from the rule of the game. Only the color of a birth and
+// -enemy-margin are decided outside the table.
+func (g *Game) cellChangeTable(t *Transitions, x, y int) uint8 {
+	if g.pinned != nil && g.pinned[x][y] != EMPTY {
+		return g.pinned[x][y]
+	}
+
+	cell := g.grid[x][y]
+	if cell >= DEAD {
+		return t[cell][0]
+	}
+
+	blue_count, orange_count := g.CountNeighbors(x, y)
+	next := t[cell][blue_count+orange_count]
+	switch {
+	case next == BORN:
+		if float64(blue_count) > float64(orange_count)+g.birthBias {
+			return BLUE
+		}
+		return ORANGE
+	case next == cell && cell != EMPTY && g.outnumbered(cell, blue_count, orange_count):
+		return DEAD
+	}
+	return next
+}
+
+// BenchmarkRule runs the game for the given number of generations, working
+// out every cell with both CellChange and cellChangeTable on one goroutine.
+// It fails at the first cell the two disagree on and otherwise writes the
+// time each took per cell.
+func (g *Game) BenchmarkRule(w io.Writer, generations int) error {
+	table := make([][]uint8, g.width)
+	for x := range table {
+		table[x] = make([]uint8, g.height)
+	}
+	var branchyTime, tableTime time.Duration
+	for range generations {
+		start := time.Now()
+		for x := range g.width {
+			for y := range g.height {
+				g.nextGrid[x][y] = g.CellChange(x, y)
+			}
+		}
+		branchyTime += time.Since(start)
+
+		start = time.Now()
+		transitions := newTransitions(g.rule)
+		for x := range g.width {
+			for y := range g.height {
+				table[x][y] = g.cellChangeTable(transitions, x, y)
+			}
+		}
+		tableTime += time.Since(start)
+
+		for x := range g.width {
+			if !slices.Equal(g.nextGrid[x], table[x]) {
+				return fmt.Errorf("generation %d: the strategies disagree in column %d", g.generation, x)
+			}
+		}
+		g.Swap()
+	}
+
+	cells := float64(g.width * g.height * generations)
+	fmt.Fprintf(w, "branchy  %.2f ns/cell\n", float64(branchyTime.Nanoseconds())/cells)
+	fmt.Fprintf(w, "table    %.2f ns/cell\n", float64(tableTime.Nanoseconds())/cells)
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import "testing"
+
+func TestCellChangeTableMatches(t *testing.T) {
+	// The eight neighbors of the middle cell, the first ones blue
+	neighbors := [8][2]int{{1, 1}, {2, 1}, {3, 1}, {1, 2}, {3, 2}, {1, 3}, {2, 3}, {3, 3}}
+	for _, rule := range []string{"B3/S23", "B36/S23", "B2/S"} {
+		for _, c := range []struct {
+			margin int
+			bias   float64
+		}{{0, 0}, {1, 0}, {0, 1.5}, {2, -1}} {
+			g := makeGame(5, 5)
+			g.rule = mustParseRule(rule)
+			g.enemyMargin = c.margin
+			g.birthBias = c.bias
+			table := newTransitions(g.rule)
+			for state := range uint8(MAX_STATE + 1) {
+				for count := range 9 {
+					for blue := range count + 1 {
+						for i, n := range neighbors {
+							switch {
+							case i < blue:
+								g.grid[n[0]][n[1]] = BLUE
+							case i < count:
+								g.grid[n[0]][n[1]] = ORANGE
+							default:
+								g.grid[n[0]][n[1]] = EMPTY
+							}
+						}
+						g.grid[2][2] = state
+						if want, got := g.CellChange(2, 2), g.cellChangeTable(table, 2, 2); got != want {
+							t.Errorf("%s margin %d bias %v: %s with %d blue and %d orange neighbors became %s, want %s",
+								rule, c.margin, c.bias, stateNames[state], blue, count-blue, stateNames[got], stateNames[want])
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkCellChange works out one generation of a seeded board cell by
+// cell, the way -benchmark-rule does
+func BenchmarkCellChange(b *testing.B) {
+	for _, strategy := range []string{"branchy", "table"} {
+		b.Run(strategy, func(b *testing.B) {
+			g := benchGame(b, 500, 500)
+			b.ResetTimer()
+			for range b.N {
+				if strategy == "branchy" {
+					for x := range g.width {
+						for y := range g.height {
+							g.nextGrid[x][y] = g.CellChange(x, y)
+						}
+					}
+					continue
+				}
+				table := newTransitions(g.rule)
+				for x := range g.width {
+					for y := range g.height {
+						g.nextGrid[x][y] = g.cellChangeTable(table, x, y)
+					}
+				}
+			}
+		})
+	}
+}